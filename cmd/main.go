@@ -6,6 +6,7 @@ import (
 	"simple-blog/internal/database"
 	"simple-blog/internal/model"
 	"simple-blog/internal/routes"
+	"simple-blog/internal/service"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -17,6 +18,18 @@ func main() {
 	// 初始化管理员账号
 	initAdmin()
 
+	// 为早期文章生成 slug
+	new(service.PostService).BackfillSlugs()
+	// 为早期标签补充规范化名称并合并重复标签
+	new(service.TagService).NormalizeTags()
+
+	// 启动后台任务
+	service.StartMailWorker()
+	service.StartDataExportWorker()
+	service.StartAccountDeletionWorker()
+	service.StartRecommendationWorker()
+	service.StartHotRankingWorker()
+
 	// 2. 初始化路由
 	r := routes.SetupRouter()
 
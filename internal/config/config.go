@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -33,16 +34,102 @@ func GetDBConfig() DBConfig {
 
 // AppConfig 应用配置
 type AppConfig struct {
+	Name      string // 站点名称，用于两步验证等场景的显示
 	Port      string
 	UploadDir string
+	BaseURL   string // 前端站点地址，用于生成邮件中的链接
+	ExportDir string // 个人数据导出文件目录，不能位于公开的上传目录下
+
+	LoginAttemptStore string // 登录失败计数存储：memory 或 database
 }
 
 // GetAppConfig 获取应用配置
 func GetAppConfig() AppConfig {
 	return AppConfig{
+		Name:      getEnv("APP_NAME", "Simple Blog"),
 		Port:      getEnv("APP_PORT", "8080"),
 		UploadDir: getEnv("UPLOAD_DIR", "uploads"),
+		BaseURL:   getEnv("APP_BASE_URL", "http://localhost:5173"),
+		ExportDir: getEnv("EXPORT_DIR", "exports"),
+
+		LoginAttemptStore: getEnv("LOGIN_ATTEMPT_STORE", "database"),
+	}
+}
+
+// SMTPConfig 邮件服务配置
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// GetSMTPConfig 获取邮件服务配置
+func GetSMTPConfig() SMTPConfig {
+	return SMTPConfig{
+		Host:     getEnv("SMTP_HOST", ""),
+		Port:     getEnv("SMTP_PORT", "25"),
+		Username: getEnv("SMTP_USERNAME", ""),
+		Password: getEnv("SMTP_PASSWORD", ""),
+		From:     getEnv("SMTP_FROM", "no-reply@localhost"),
+	}
+}
+
+// MailConfig 邮件子系统配置
+type MailConfig struct {
+	Driver  string // smtp, log, file
+	FileDir string // file 驱动的输出目录
+	SMTP    SMTPConfig
+}
+
+// GetMailConfig 获取邮件子系统配置
+func GetMailConfig() MailConfig {
+	return MailConfig{
+		Driver:  getEnv("MAIL_DRIVER", "smtp"),
+		FileDir: getEnv("MAIL_FILE_DIR", "mail_outbox"),
+		SMTP:    GetSMTPConfig(),
+	}
+}
+
+// OAuthProviderConfig 第三方登录提供方配置。
+// 配置 Issuer 时按 OpenID Connect 发现文档工作；GitHub、Gitee 等纯 OAuth2 提供方使用内置的端点地址
+type OAuthProviderConfig struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	Issuer       string
+	AuthURL      string
+	TokenURL     string
+	UserInfoURL  string
+	Scopes       string // 空格分隔
+	RedirectURL  string
+}
+
+// GetOAuthProviders 读取 OAUTH_PROVIDERS（逗号分隔）中列出的提供方，
+// 每个提供方的配置来自 OAUTH_<NAME>_CLIENT_ID、OAUTH_<NAME>_CLIENT_SECRET 等环境变量
+func GetOAuthProviders() []OAuthProviderConfig {
+	var providers []OAuthProviderConfig
+	baseURL := GetAppConfig().BaseURL
+	for _, name := range strings.Split(getEnv("OAUTH_PROVIDERS", ""), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OAUTH_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OAuthProviderConfig{
+			Name:         name,
+			ClientID:     getEnv(prefix+"CLIENT_ID", ""),
+			ClientSecret: getEnv(prefix+"CLIENT_SECRET", ""),
+			Issuer:       getEnv(prefix+"ISSUER", ""),
+			AuthURL:      getEnv(prefix+"AUTH_URL", ""),
+			TokenURL:     getEnv(prefix+"TOKEN_URL", ""),
+			UserInfoURL:  getEnv(prefix+"USERINFO_URL", ""),
+			Scopes:       getEnv(prefix+"SCOPES", ""),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", baseURL+"/oauth/callback/"+name),
+		})
 	}
+	return providers
 }
 
 func getEnv(key, fallback string) string {
@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var accessTokenService = new(service.AccessTokenService)
+
+// GetAccessTokens 列出当前用户的个人访问令牌
+func GetAccessTokens(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tokens, err := accessTokenService.ListTokens(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, tokens)
+}
+
+// CreateAccessToken 创建个人访问令牌，明文令牌仅在此返回一次
+func CreateAccessToken(c *gin.Context) {
+	var input struct {
+		Name          string   `json:"name" binding:"required,max=100"`
+		Scopes        []string `json:"scopes" binding:"required"`
+		ExpiresInDays int      `json:"expires_in_days" binding:"min=0"` // 0 表示永不过期
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	userID, _ := c.Get("user_id")
+	raw, token, err := accessTokenService.CreateToken(userID.(uint), input.Name, input.Scopes, expiresAt)
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, gin.H{
+		"token":        raw,
+		"access_token": token,
+	})
+}
+
+// RevokeAccessToken 吊销个人访问令牌
+func RevokeAccessToken(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := accessTokenService.RevokeToken(userID.(uint), uint(id)); err != nil {
+		common.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var accountService = new(service.AccountService)
+
+// ChangePassword 修改密码
+func ChangePassword(c *gin.Context) {
+	var input struct {
+		OldPassword string `json:"old_password" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := accountService.ChangePassword(userID.(uint), c.GetString("session_id"), input.OldPassword, input.NewPassword); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
+
+// ForgotPassword 申请密码重置邮件
+func ForgotPassword(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 无论邮箱是否存在都返回成功
+	if err := accountService.RequestPasswordReset(input.Email); err != nil {
+		fmt.Printf("ForgotPassword: %v\n", err)
+	}
+	common.Success(c, gin.H{"message": "If the email exists, a reset link has been sent"})
+}
+
+// ResetPassword 通过邮件中的令牌重置密码
+func ResetPassword(c *gin.Context) {
+	var input struct {
+		Token       string `json:"token" binding:"required"`
+		NewPassword string `json:"new_password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := accountService.ResetPassword(input.Token, input.NewPassword); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
+
+// VerifyEmail 通过邮件中的令牌验证邮箱
+func VerifyEmail(c *gin.Context) {
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := accountService.VerifyEmail(input.Token); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
+
+// ResendEmailVerification 重新发送邮箱验证邮件
+func ResendEmailVerification(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if err := accountService.SendEmailVerification(userID.(uint)); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var auditService = new(service.AuditService)
+
+// getActor 从上下文中构造当前操作者信息
+func getActor(c *gin.Context) service.Actor {
+	actor := service.Actor{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		actor.UserID = userID.(uint)
+	}
+	if role, exists := c.Get("user_role"); exists {
+		actor.Role = role.(string)
+	}
+	return actor
+}
+
+func parseAuditFilter(c *gin.Context) (service.AuditFilter, error) {
+	var filter service.AuditFilter
+	actorID, _ := strconv.Atoi(c.Query("actor_id"))
+	targetID, _ := strconv.Atoi(c.Query("target_id"))
+	filter.ActorID = uint(actorID)
+	filter.TargetID = uint(targetID)
+	filter.Action = c.Query("action")
+	filter.TargetType = c.Query("target_type")
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from time: %s", from)
+		}
+		filter.From = &t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to time: %s", to)
+		}
+		filter.To = &t
+	}
+	return filter, nil
+}
+
+// GetAuditLogs 分页查询审计日志（仅管理员）
+func GetAuditLogs(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	logs, total, err := auditService.ListLogs(filter, page, pageSize)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	common.Success(c, gin.H{
+		"list": logs,
+		"meta": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total":        total,
+		},
+	})
+}
+
+// ExportAuditLogs 导出审计日志为 CSV 或 JSON 文件（仅管理员）
+func ExportAuditLogs(c *gin.Context) {
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	var contentType string
+	switch format {
+	case "csv":
+		contentType = "text/csv; charset=utf-8"
+	case "json":
+		contentType = "application/json; charset=utf-8"
+	default:
+		common.Error(c, http.StatusBadRequest, "format must be csv or json")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := auditService.ExportLogs(filter, format, &buf); err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Disposition", "attachment; filename="+filename)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var blockService = new(service.BlockService)
+
+// BlockUser 屏蔽用户
+func BlockUser(c *gin.Context) {
+	updateBlock(c, blockService.Block)
+}
+
+// UnblockUser 取消屏蔽
+func UnblockUser(c *gin.Context) {
+	updateBlock(c, blockService.Unblock)
+}
+
+// MuteUser 静音用户
+func MuteUser(c *gin.Context) {
+	updateBlock(c, blockService.Mute)
+}
+
+// UnmuteUser 取消静音
+func UnmuteUser(c *gin.Context) {
+	updateBlock(c, blockService.Unmute)
+}
+
+// GetBlockedUsers 获取当前用户屏蔽的用户
+func GetBlockedUsers(c *gin.Context) {
+	listBlocks(c, service.BlockKindBlock)
+}
+
+// GetMutedUsers 获取当前用户静音的用户
+func GetMutedUsers(c *gin.Context) {
+	listBlocks(c, service.BlockKindMute)
+}
+
+func updateBlock(c *gin.Context, op func(userID, targetID uint) error) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := op(userID.(uint), uint(targetID)); err != nil {
+		switch err.Error() {
+		case "user not found":
+			common.Error(c, http.StatusNotFound, err.Error())
+		case "cannot block yourself":
+			common.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			common.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	common.Success(c, nil)
+}
+
+func listBlocks(c *gin.Context, kind string) {
+	userID, _ := c.Get("user_id")
+	list, err := blockService.List(userID.(uint), kind)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, list)
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var categoryService = new(service.CategoryService)
+
+type categoryInput struct {
+	ParentID    *uint  `json:"parent_id"`
+	Name        string `json:"name" binding:"required,max=50"`
+	Slug        string `json:"slug" binding:"max=80"`
+	Description string `json:"description" binding:"max=500"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+func (in categoryInput) toService() service.CategoryInput {
+	return service.CategoryInput{
+		ParentID:    in.ParentID,
+		Name:        in.Name,
+		Slug:        in.Slug,
+		Description: in.Description,
+		SortOrder:   in.SortOrder,
+	}
+}
+
+// GetCategories 获取分类树及每个分类的文章数
+func GetCategories(c *gin.Context) {
+	tree, err := categoryService.Tree()
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, tree)
+}
+
+// CreateCategory 创建分类（仅管理员）
+func CreateCategory(c *gin.Context) {
+	var input categoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	category, err := categoryService.CreateCategory(getActor(c), input.toService())
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	common.Success(c, category)
+}
+
+// UpdateCategory 修改分类（仅管理员）
+func UpdateCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	var input categoryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	category, err := categoryService.UpdateCategory(uint(id), getActor(c), input.toService())
+	if err != nil {
+		categoryError(c, err)
+		return
+	}
+	common.Success(c, category)
+}
+
+// DeleteCategory 删除分类（仅管理员）
+func DeleteCategory(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	if err := categoryService.DeleteCategory(uint(id), getActor(c)); err != nil {
+		categoryError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+func categoryError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "unauthorized":
+		common.Error(c, http.StatusForbidden, err.Error())
+	case "category not found":
+		common.Error(c, http.StatusNotFound, err.Error())
+	default:
+		common.Error(c, http.StatusBadRequest, err.Error())
+	}
+}
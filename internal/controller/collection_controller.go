@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var collectionService = new(service.CollectionService)
+
+type collectionInput struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+// CreateCollection 创建收藏夹
+func CreateCollection(c *gin.Context) {
+	var input collectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	collection, err := collectionService.CreateCollection(userID.(uint), input.Name, input.Description, input.IsPublic)
+	if err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, collection)
+}
+
+// UpdateCollection 修改收藏夹
+func UpdateCollection(c *gin.Context) {
+	id, ok := collectionIDParam(c)
+	if !ok {
+		return
+	}
+	var input collectionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	collection, err := collectionService.UpdateCollection(userID.(uint), id, input.Name, input.Description, input.IsPublic)
+	if err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, collection)
+}
+
+// DeleteCollection 删除收藏夹
+func DeleteCollection(c *gin.Context) {
+	id, ok := collectionIDParam(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := collectionService.DeleteCollection(userID.(uint), id); err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// GetCollection 获取收藏夹及其中的文章，公开的收藏夹任何人都可以访问
+func GetCollection(c *gin.Context) {
+	id, ok := collectionIDParam(c)
+	if !ok {
+		return
+	}
+
+	collection, err := collectionService.GetCollection(id, getActor(c))
+	if err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, collection)
+}
+
+// GetUserCollections 获取用户的收藏夹列表
+func GetUserCollections(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	list, err := collectionService.ListUserCollections(userID, getActor(c))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, list)
+}
+
+// GetMyCollections 获取当前用户的收藏夹列表
+func GetMyCollections(c *gin.Context) {
+	actor := getActor(c)
+	list, err := collectionService.ListUserCollections(actor.UserID, actor)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, list)
+}
+
+// AddCollectionItem 将文章放入收藏夹
+func AddCollectionItem(c *gin.Context) {
+	id, ok := collectionIDParam(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		PostID uint   `json:"post_id" binding:"required"`
+		Note   string `json:"note" binding:"max=1000"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	item, err := collectionService.AddItem(userID.(uint), id, input.PostID, input.Note)
+	if err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, item)
+}
+
+// UpdateCollectionItem 修改收藏夹中文章的备注
+func UpdateCollectionItem(c *gin.Context) {
+	id, ok := collectionIDParam(c)
+	if !ok {
+		return
+	}
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+	var input struct {
+		Note string `json:"note" binding:"max=1000"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := collectionService.UpdateItemNote(userID.(uint), id, uint(postID), input.Note); err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// RemoveCollectionItem 从收藏夹中移除文章
+func RemoveCollectionItem(c *gin.Context) {
+	id, ok := collectionIDParam(c)
+	if !ok {
+		return
+	}
+	postID, err := strconv.Atoi(c.Param("post_id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := collectionService.RemoveItem(userID.(uint), id, uint(postID)); err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// ReorderCollectionItems 调整收藏夹中文章的顺序
+func ReorderCollectionItems(c *gin.Context) {
+	id, ok := collectionIDParam(c)
+	if !ok {
+		return
+	}
+	var input struct {
+		PostIDs []uint `json:"post_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := collectionService.ReorderItems(userID.(uint), id, input.PostIDs); err != nil {
+		collectionError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+func collectionIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid collection ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func collectionError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "collection not found", "post not found", "item not found":
+		common.Error(c, http.StatusNotFound, err.Error())
+	case "name is required", "collection is full":
+		common.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		common.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
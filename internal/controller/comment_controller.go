@@ -28,7 +28,14 @@ func CreateComment(c *gin.Context) {
 	comment.UserID = userID.(uint)
 
 	if err := commentService.CreateComment(&comment); err != nil {
-		common.Error(c, http.StatusInternalServerError, "Failed to create comment")
+		switch err.Error() {
+		case "blocked":
+			common.Error(c, http.StatusForbidden, "You cannot comment on this post")
+		case "post not found":
+			common.Error(c, http.StatusNotFound, "Post not found")
+		default:
+			common.Error(c, http.StatusInternalServerError, "Failed to create comment")
+		}
 		return
 	}
 
@@ -46,10 +53,7 @@ func DeleteComment(c *gin.Context) {
 		return
 	}
 
-	userID, _ := c.Get("user_id")
-	role, _ := c.Get("role")
-
-	if err := commentService.DeleteComment(uint(id), userID.(uint), role.(string)); err != nil {
+	if err := commentService.DeleteComment(uint(id), getActor(c)); err != nil {
 		common.Error(c, http.StatusForbidden, err.Error())
 		return
 	}
@@ -64,8 +68,12 @@ func GetComments(c *gin.Context) {
 		return
 	}
 
-	comments, err := commentService.GetCommentsByPostID(uint(postID))
+	comments, err := commentService.GetCommentsByPostID(uint(postID), getActor(c))
 	if err != nil {
+		if err.Error() == "post not found" {
+			common.Error(c, http.StatusNotFound, "Post not found")
+			return
+		}
 		common.Error(c, http.StatusInternalServerError, "Failed to fetch comments")
 		return
 	}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var feedService = service.NewFeedService()
+
+// GetFeed 获取个人时间线（关注的作者和标签的文章），使用游标翻页
+func GetFeed(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	userID, _ := c.Get("user_id")
+
+	posts, next, err := feedService.Timeline(userID.(uint), c.Query("cursor"), limit)
+	if err != nil {
+		if err.Error() == "invalid cursor" {
+			common.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	common.Success(c, gin.H{
+		"list":        posts,
+		"next_cursor": next,
+	})
+}
+
+// FollowTag 关注标签
+func FollowTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tag, err := tagService.FollowTag(userID.(uint), c.Param("name"))
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+	common.Success(c, tag)
+}
+
+// UnfollowTag 取消关注标签
+func UnfollowTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if err := tagService.UnfollowTag(userID.(uint), c.Param("name")); err != nil {
+		tagError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// GetFollowedTags 获取当前用户关注的标签
+func GetFollowedTags(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	tags, err := tagService.GetFollowedTags(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, tags)
+}
@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var followRequestService = new(service.FollowRequestService)
+
+// GetFollowRequests 获取收到的待处理关注请求
+func GetFollowRequests(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	requests, err := followRequestService.ListIncoming(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, requests)
+}
+
+// ApproveFollowRequest 通过关注请求
+func ApproveFollowRequest(c *gin.Context) {
+	handleFollowRequest(c, followRequestService.Approve)
+}
+
+// RejectFollowRequest 拒绝关注请求
+func RejectFollowRequest(c *gin.Context) {
+	handleFollowRequest(c, followRequestService.Reject)
+}
+
+func handleFollowRequest(c *gin.Context, op func(userID, requestID uint) error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid request ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := op(userID.(uint), uint(id)); err != nil {
+		if err.Error() == "follow request not found" {
+			common.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
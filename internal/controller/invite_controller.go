@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var inviteService = new(service.InviteService)
+var captchaService = service.NewCaptchaService()
+
+// GetCaptcha 获取图形验证码，同一 IP 请求过于频繁时返回 429 并带上 Retry-After
+func GetCaptcha(c *gin.Context) {
+	id, image, err := captchaService.New(c.ClientIP())
+	if err != nil {
+		var limited *service.CaptchaRateLimitedError
+		if errors.As(err, &limited) {
+			c.Header("Retry-After", strconv.Itoa(int(limited.RetryAfter.Seconds())))
+			common.Error(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, gin.H{"captcha_id": id, "image": image})
+}
+
+// GetRegistrationMode 获取当前注册方式，供注册页决定是否显示邀请码输入框
+func GetRegistrationMode(c *gin.Context) {
+	common.Success(c, gin.H{"mode": inviteService.RegistrationMode()})
+}
+
+// GetMyInvites 列出当前用户生成的邀请码
+func GetMyInvites(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	listInvites(c, userID.(uint))
+}
+
+// CreateMyInvite 当前用户生成一次性邀请码
+func CreateMyInvite(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	invite, err := inviteService.CreateUserInvite(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusForbidden, err.Error())
+		return
+	}
+	common.Success(c, invite)
+}
+
+// GetInvites 查看所有邀请码（仅管理员）
+func GetInvites(c *gin.Context) {
+	listInvites(c, 0)
+}
+
+// CreateInvite 生成邀请码（仅管理员）
+func CreateInvite(c *gin.Context) {
+	var input struct {
+		MaxUses       int    `json:"max_uses" binding:"required,min=1"`
+		ExpiresInDays int    `json:"expires_in_days" binding:"min=0"` // 0 表示永不过期
+		Note          string `json:"note" binding:"max=255"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var expiresAt *time.Time
+	if input.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	invite, err := inviteService.CreateInvite(getActor(c), input.MaxUses, expiresAt, input.Note)
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, invite)
+}
+
+// RevokeInvite 吊销邀请码
+func RevokeInvite(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid invite ID")
+		return
+	}
+
+	if err := inviteService.RevokeInvite(uint(id), getActor(c)); err != nil {
+		common.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
+
+func listInvites(c *gin.Context, createdBy uint) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	invites, total, err := inviteService.ListInvites(createdBy, page, pageSize)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	common.Success(c, gin.H{
+		"list": invites,
+		"meta": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total":        total,
+		},
+	})
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var mailService = service.NewMailService()
+
+// GetFailedMails 查看发送失败的邮件（仅管理员）
+func GetFailedMails(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	items, total, err := mailService.ListFailed(page, pageSize)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	common.Success(c, gin.H{
+		"list": items,
+		"meta": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total":        total,
+		},
+	})
+}
+
+// RetryFailedMail 重新投递发送失败的邮件（仅管理员）
+func RetryFailedMail(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid mail ID")
+		return
+	}
+
+	if err := mailService.Retry(uint(id)); err != nil {
+		common.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var mentionService = new(service.MentionService)
+
+// GetMentions 获取提及当前用户的文章和评论
+func GetMentions(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	userID, _ := c.Get("user_id")
+	mentions, total, err := mentionService.ListMentions(userID.(uint), page, pageSize)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, gin.H{
+		"list": mentions,
+		"meta": gin.H{
+			"current_page": page,
+			"page_size":    pageSize,
+			"total":        total,
+		},
+	})
+}
+
+// UpdateMentionNotify 设置接收哪些人的提及通知：everyone, following, none
+func UpdateMentionNotify(c *gin.Context) {
+	var input struct {
+		MentionNotify string `json:"mention_notify" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := mentionService.SetMentionNotify(userID.(uint), input.MentionNotify); err != nil {
+		if err.Error() == "invalid mention notify setting" {
+			common.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, gin.H{"mention_notify": input.MentionNotify})
+}
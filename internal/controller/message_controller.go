@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var messageService = new(service.MessageService)
+
+type messageInput struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// SendMessage 向用户发送私信
+func SendMessage(c *gin.Context) {
+	recipientID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	var input messageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	message, err := messageService.Send(userID.(uint), recipientID, input.Content)
+	if err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, message)
+}
+
+// ReplyMessage 在会话中发送私信
+func ReplyMessage(c *gin.Context) {
+	id, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	var input messageInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	message, err := messageService.Reply(userID.(uint), id, input.Content)
+	if err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, message)
+}
+
+// GetConversations 获取当前用户的会话列表，使用游标翻页
+func GetConversations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	userID, _ := c.Get("user_id")
+
+	conversations, next, err := messageService.ListConversations(userID.(uint), c.Query("cursor"), limit)
+	if err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, gin.H{
+		"list":        conversations,
+		"next_cursor": next,
+	})
+}
+
+// GetConversation 获取会话概况
+func GetConversation(c *gin.Context) {
+	id, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	conversation, err := messageService.GetConversation(userID.(uint), id)
+	if err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, conversation)
+}
+
+// GetMessages 获取会话中的私信，从最新的开始翻页
+func GetMessages(c *gin.Context) {
+	id, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+	userID, _ := c.Get("user_id")
+
+	messages, next, err := messageService.GetMessages(userID.(uint), id, c.Query("cursor"), limit)
+	if err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, gin.H{
+		"list":        messages,
+		"next_cursor": next,
+	})
+}
+
+// MarkConversationRead 将会话标记为已读
+func MarkConversationRead(c *gin.Context) {
+	id, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := messageService.MarkRead(userID.(uint), id); err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// MuteConversation 静音会话
+func MuteConversation(c *gin.Context) {
+	setConversationMuted(c, true)
+}
+
+// UnmuteConversation 取消静音会话
+func UnmuteConversation(c *gin.Context) {
+	setConversationMuted(c, false)
+}
+
+// GetUnreadMessageCount 获取未读私信数，不含静音的会话
+func GetUnreadMessageCount(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	count, err := messageService.UnreadCount(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, count)
+}
+
+// UpdateMessagePrivacy 设置允许哪些人发私信：everyone, following
+func UpdateMessagePrivacy(c *gin.Context) {
+	var input struct {
+		MessageFrom string `json:"message_from" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := messageService.SetMessageFrom(userID.(uint), input.MessageFrom); err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"message_from": input.MessageFrom})
+}
+
+func setConversationMuted(c *gin.Context, muted bool) {
+	id, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := messageService.SetMuted(userID.(uint), id, muted); err != nil {
+		messageError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"muted": muted})
+}
+
+func conversationIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid conversation ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func messageError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "user not found", "conversation not found":
+		common.Error(c, http.StatusNotFound, err.Error())
+	case "blocked", "recipient does not accept messages from you":
+		common.Error(c, http.StatusForbidden, err.Error())
+	case "content is required", "message too long", "cannot message yourself", "invalid cursor", "invalid message setting":
+		common.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		common.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
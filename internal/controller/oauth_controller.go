@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/config"
+	"simple-blog/internal/service"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var oauthService = service.NewOAuthService()
+
+// oauthStateCookie 保存发起授权的浏览器的 state，回调时与提交的 state 比对
+const oauthStateCookie = "oauth_state"
+
+// GetOAuthProviders 获取可用的第三方登录方式
+func GetOAuthProviders(c *gin.Context) {
+	common.Success(c, oauthService.ListProviders())
+}
+
+// OAuthAuthorize 获取第三方登录的授权地址，前端跳转到该地址完成授权
+func OAuthAuthorize(c *gin.Context) {
+	authURL, state, err := oauthService.StartLogin(c.Request.Context(), c.Param("provider"), 0)
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	setOAuthStateCookie(c, state, int(service.OAuthStateTTL.Seconds()))
+	common.Success(c, gin.H{"authorize_url": authURL})
+}
+
+// OAuthCallback 前端回调页将授权码和 state 提交到此处，完成登录或绑定。
+// 绑定时需携带发起绑定的用户的登录令牌
+func OAuthCallback(c *gin.Context) {
+	var input struct {
+		Code  string `json:"code" binding:"required"`
+		State string `json:"state" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	browserState, _ := c.Cookie(oauthStateCookie)
+	setOAuthStateCookie(c, "", -1)
+	callerID := c.GetUint("user_id")
+
+	result, err := oauthService.CompleteLogin(c.Request.Context(), c.Param("provider"), input.Code, input.State, browserState, callerID, clientInfo(c))
+	if err != nil {
+		common.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+	common.Success(c, result)
+}
+
+// LinkIdentity 为当前用户绑定第三方账号，返回授权地址
+func LinkIdentity(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	authURL, state, err := oauthService.StartLogin(c.Request.Context(), c.Param("provider"), userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	setOAuthStateCookie(c, state, int(service.OAuthStateTTL.Seconds()))
+	common.Success(c, gin.H{"authorize_url": authURL})
+}
+
+// setOAuthStateCookie 写入或清除（maxAge < 0）保存 state 的 Cookie，前端脚本无法读取
+func setOAuthStateCookie(c *gin.Context, state string, maxAge int) {
+	secure := strings.HasPrefix(config.GetAppConfig().BaseURL, "https://")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookie, state, maxAge, "/api", "", secure, true)
+}
+
+// GetIdentities 列出当前用户绑定的第三方账号
+func GetIdentities(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	identities, err := oauthService.ListIdentities(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, identities)
+}
+
+// UnlinkIdentity 解除第三方账号绑定
+func UnlinkIdentity(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid identity ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := oauthService.UnlinkIdentity(userID.(uint), uint(id)); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
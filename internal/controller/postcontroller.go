@@ -2,10 +2,12 @@ package controller
 
 import (
 	"net/http"
+	"net/url"
 	"simple-blog/internal/common"
 	"simple-blog/internal/model"
 	"simple-blog/internal/service"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,6 +34,10 @@ func CreatePost(c *gin.Context) {
 
 	// 2. 调用 Service
 	if err := postService.CreatePost(&post); err != nil {
+		if isPostInputError(err) {
+			common.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -44,7 +50,7 @@ func CreatePost(c *gin.Context) {
 func GetPostDetail(c *gin.Context) {
 	id := c.Param("id")
 
-	post, err := postService.GetPostDetail(id)
+	post, err := postService.GetPostDetail(id, getActor(c))
 	if err != nil {
 		common.Error(c, http.StatusNotFound, "Post not found")
 		return
@@ -53,12 +59,66 @@ func GetPostDetail(c *gin.Context) {
 	common.Success(c, post)
 }
 
+// GetPostBySlug 通过作者用户名和 slug 获取文章详情，旧用户名或旧 slug 会重定向到当前地址
+func GetPostBySlug(c *gin.Context) {
+	username := c.Param("username")
+	postSlug := c.Param("slug")
+
+	userID, currentUsername, err := userService.ResolveUsername(username)
+	if err != nil {
+		common.Error(c, http.StatusNotFound, "User not found")
+		return
+	}
+	postID, currentSlug, err := postService.ResolveSlug(userID, postSlug)
+	if err != nil {
+		common.Error(c, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	if !strings.EqualFold(username, currentUsername) || postSlug != currentSlug {
+		path := c.Request.URL.Path
+		target := url.URL{
+			Path:     path[:strings.LastIndex(path, "/u/")] + "/u/" + currentUsername + "/posts/" + currentSlug,
+			RawQuery: c.Request.URL.RawQuery,
+		}
+		c.Redirect(http.StatusMovedPermanently, target.String())
+		return
+	}
+
+	post, err := postService.GetPostDetail(strconv.Itoa(int(postID)), getActor(c))
+	if err != nil {
+		common.Error(c, http.StatusNotFound, "Post not found")
+		return
+	}
+	common.Success(c, post)
+}
+
+// isPostInputError 判断是否为文章参数错误（slug、分类或标签无效），应返回 400
+func isPostInputError(err error) bool {
+	switch err.Error() {
+	case "invalid slug", "slug already in use", "category not found", "tag name too long", "cannot edit a repost":
+		return true
+	}
+	return false
+}
+
 // GetPostList 获取文章列表（分页）
 func GetPostList(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 	tagName := c.Query("tag")
 	userID, _ := strconv.Atoi(c.Query("user_id"))
+
+	// 分类可以使用 slug 或ID，筛选结果包含子分类
+	var categoryID uint
+	if key := c.Query("category"); key != "" {
+		category, err := categoryService.Resolve(key)
+		if err != nil {
+			common.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		categoryID = category.ID
+	}
 	status := c.DefaultQuery("status", "published")
 	orderBy := c.DefaultQuery("order_by", "created_at")
 	keyword := c.Query("keyword")
@@ -70,7 +130,7 @@ func GetPostList(c *gin.Context) {
 		status = "" // 不过滤状态
 	}
 
-	posts, total, err := postService.GetPostList(page, pageSize, tagName, uint(userID), status, orderBy, keyword)
+	posts, total, err := postService.GetPostList(page, pageSize, tagName, categoryID, uint(userID), status, orderBy, keyword, getActor(c))
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -86,11 +146,15 @@ func GetPostList(c *gin.Context) {
 	})
 }
 
-// GetHotPosts 获取热门文章
+// GetHotPosts 获取热门文章，window 可选 24h、7d（默认）、30d
 func GetHotPosts(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
-	posts, err := postService.GetHotPosts(limit)
+	posts, err := postService.GetHotPosts(limit, c.Query("window"))
 	if err != nil {
+		if err.Error() == "invalid window" {
+			common.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -99,10 +163,8 @@ func GetHotPosts(c *gin.Context) {
 
 // GetUserPosts 获取指定用户的文章列表
 func GetUserPosts(c *gin.Context) {
-	userIDStr := c.Param("id")
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+	userID, ok := userIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -110,7 +172,7 @@ func GetUserPosts(c *gin.Context) {
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
 	// 个人主页只显示已发布的文章
-	posts, _, err := postService.GetPostList(page, pageSize, "", uint(userID), "published", "created_at", "")
+	posts, _, err := postService.GetPostList(page, pageSize, "", 0, userID, "published", "created_at", "", getActor(c))
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -123,25 +185,24 @@ func GetUserPosts(c *gin.Context) {
 func UpdatePost(c *gin.Context) {
 	id := c.Param("id")
 
-	userId, exists := c.Get("user_id")
-	if !exists {
+	if _, exists := c.Get("user_id"); !exists {
 		common.Error(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
 
-	userRole, _ := c.Get("user_role")
-
 	var input model.Post
 	if err := c.ShouldBindJSON(&input); err != nil {
 		common.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err := postService.UpdatePost(id, userId.(uint), userRole.(string), &input); err != nil {
+	if err := postService.UpdatePost(id, getActor(c), &input); err != nil {
 		if err.Error() == "unauthorized" {
 			common.Error(c, http.StatusForbidden, "You are not authorized to update this post")
 		} else if err.Error() == "post not found" {
 			common.Error(c, http.StatusNotFound, "Post not found")
+		} else if isPostInputError(err) {
+			common.Error(c, http.StatusBadRequest, err.Error())
 		} else {
 			common.Error(c, http.StatusInternalServerError, "Failed to update post")
 		}
@@ -155,14 +216,12 @@ func UpdatePost(c *gin.Context) {
 func DeletePost(c *gin.Context) {
 	id := c.Param("id")
 
-	userId, exists := c.Get("user_id")
-	if !exists {
+	if _, exists := c.Get("user_id"); !exists {
 		common.Error(c, http.StatusUnauthorized, "User not found in context")
 		return
 	}
-	userRole, _ := c.Get("user_role")
 
-	if err := postService.DeletePost(id, userId.(uint), userRole.(string)); err != nil {
+	if err := postService.DeletePost(id, getActor(c)); err != nil {
 		if err.Error() == "unauthorized" {
 			common.Error(c, http.StatusForbidden, "You are not authorized to delete this post")
 		} else if err.Error() == "post not found" {
@@ -176,9 +235,10 @@ func DeletePost(c *gin.Context) {
 	common.Success(c, "Post deleted successfully")
 }
 
-// GetTags 获取所有标签
+// GetTags 获取所有标签及使用次数，sort=popular 时按使用次数排序
 func GetTags(c *gin.Context) {
-	tags, err := postService.GetAllTags()
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	tags, err := postService.GetAllTags(c.Query("sort"), limit)
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -186,6 +246,21 @@ func GetTags(c *gin.Context) {
 	common.Success(c, tags)
 }
 
+// GetTrendingTags 获取使用次数增长最快的标签，window 可选 24h、7d（默认）、30d
+func GetTrendingTags(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	tags, err := tagService.TrendingTags(c.Query("window"), limit)
+	if err != nil {
+		if err.Error() == "invalid window" {
+			common.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, tags)
+}
+
 // ToggleLike 处理点赞/取消点赞
 func ToggleLike(c *gin.Context) {
 	postIDStr := c.Param("id")
@@ -207,6 +282,10 @@ func ToggleLike(c *gin.Context) {
 	}
 
 	if err != nil {
+		if err.Error() == "post not found" {
+			common.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
 		common.Error(c, http.StatusInternalServerError, "Operation failed")
 		return
 	}
@@ -214,16 +293,14 @@ func ToggleLike(c *gin.Context) {
 	common.Success(c, !isLiked)
 }
 
-// GetLikedPosts 获取某用户的点赞列表
+// GetLikedPosts 获取某用户的点赞列表，私密账号的文章只对其关注者可见
 func GetLikedPosts(c *gin.Context) {
-	userIDStr := c.Param("id")
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+	userID, ok := userIDParam(c)
+	if !ok {
 		return
 	}
 
-	posts, err := postService.GetLikedPosts(uint(userID))
+	posts, err := postService.GetLikedPosts(userID, getActor(c))
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, "Failed to fetch liked posts")
 		return
@@ -275,6 +352,10 @@ func ToggleFavorite(c *gin.Context) {
 	}
 
 	if err != nil {
+		if err.Error() == "post not found" {
+			common.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
 		common.Error(c, http.StatusInternalServerError, "Operation failed")
 		return
 	}
@@ -282,16 +363,14 @@ func ToggleFavorite(c *gin.Context) {
 	common.Success(c, !isFavorited)
 }
 
-// GetFavoritePosts 获取某用户的收藏列表
+// GetFavoritePosts 获取某用户的收藏列表，私密账号的文章只对其关注者可见
 func GetFavoritePosts(c *gin.Context) {
-	userIDStr := c.Param("id")
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+	userID, ok := userIDParam(c)
+	if !ok {
 		return
 	}
 
-	posts, err := postService.GetFavoritePosts(uint(userID))
+	posts, err := postService.GetFavoritePosts(userID, getActor(c))
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, "Failed to fetch favorite posts")
 		return
@@ -302,10 +381,8 @@ func GetFavoritePosts(c *gin.Context) {
 // ToggleTop 切换个人置顶状态
 func ToggleTop(c *gin.Context) {
 	id := c.Param("id")
-	userID, _ := c.Get("user_id")
-	userRole, _ := c.Get("user_role")
 
-	if err := postService.ToggleTop(id, userID.(uint), userRole.(string)); err != nil {
+	if err := postService.ToggleTop(id, getActor(c)); err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -316,9 +393,8 @@ func ToggleTop(c *gin.Context) {
 // ToggleSystemTop 切换全站置顶状态（仅管理员）
 func ToggleSystemTop(c *gin.Context) {
 	id := c.Param("id")
-	userRole, _ := c.Get("user_role")
 
-	if err := postService.ToggleSystemTop(id, userRole.(string)); err != nil {
+	if err := postService.ToggleSystemTop(id, getActor(c)); err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
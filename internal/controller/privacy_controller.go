@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var dataExportService = new(service.DataExportService)
+var accountDeletionService = new(service.AccountDeletionService)
+
+// RequestDataExport 申请导出个人数据，导出包由后台生成
+func RequestDataExport(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	export, err := dataExportService.RequestExport(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	common.Success(c, export)
+}
+
+// GetDataExports 列出当前用户的导出记录
+func GetDataExports(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	exports, err := dataExportService.ListExports(userID.(uint))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, exports)
+}
+
+// DownloadDataExport 下载已生成的导出包
+func DownloadDataExport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid export ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	path, filename, err := dataExportService.GetExportFile(userID.(uint), uint(id))
+	if err != nil {
+		common.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	c.FileAttachment(path, filename)
+}
+
+// RequestAccountDeletion 申请注销账号，冷静期后执行
+func RequestAccountDeletion(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+		Mode     string `json:"mode" binding:"required,oneof=anonymize delete"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	scheduledAt, err := accountDeletionService.RequestDeletion(userID.(uint), input.Password, input.Mode)
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, gin.H{"deletion_scheduled_at": scheduledAt, "mode": input.Mode})
+}
+
+// CancelAccountDeletion 撤销注销申请
+func CancelAccountDeletion(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if err := accountDeletionService.CancelDeletion(userID.(uint)); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var reactionService = new(service.ReactionService)
+
+type reactionInput struct {
+	Emoji string `json:"emoji" binding:"required"`
+}
+
+// GetReactionEmojis 获取可用的表情
+func GetReactionEmojis(c *gin.Context) {
+	common.Success(c, reactionService.Emojis())
+}
+
+// TogglePostReaction 添加或取消对文章的表情回应
+func TogglePostReaction(c *gin.Context) {
+	toggleReaction(c, service.ReactionTargetPost)
+}
+
+// ToggleCommentReaction 添加或取消对评论的表情回应
+func ToggleCommentReaction(c *gin.Context) {
+	toggleReaction(c, service.ReactionTargetComment)
+}
+
+func toggleReaction(c *gin.Context, targetType string) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+	var input reactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	reacted, err := reactionService.Toggle(userID.(uint), targetType, uint(targetID), input.Emoji)
+	if err != nil {
+		switch err.Error() {
+		case "post not found", "comment not found":
+			common.Error(c, http.StatusNotFound, err.Error())
+		case "unsupported emoji":
+			common.Error(c, http.StatusBadRequest, err.Error())
+		case "blocked":
+			common.Error(c, http.StatusForbidden, "You cannot react to this content")
+		default:
+			common.Error(c, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	common.Success(c, gin.H{"emoji": input.Emoji, "reacted": reacted})
+}
@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var recommendationService = new(service.RecommendationService)
+
+// GetRelatedPosts 获取文章的相关文章
+func GetRelatedPosts(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+
+	posts, err := recommendationService.RelatedPosts(uint(postID), getActor(c), limit)
+	if err != nil {
+		if err.Error() == "post not found" {
+			common.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, posts)
+}
+
+// GetUserRecommendations 获取推荐关注的用户
+func GetUserRecommendations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	userID, _ := c.Get("user_id")
+
+	list, err := recommendationService.UserRecommendations(userID.(uint), limit)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, list)
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var repostService = new(service.RepostService)
+
+// ToggleRepost 转发或取消转发文章
+func ToggleRepost(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	reposted, err := repostService.ToggleRepost(userID.(uint), uint(postID))
+	if err != nil {
+		repostError(c, err)
+		return
+	}
+	common.Success(c, gin.H{"reposted": reposted})
+}
+
+// QuotePost 引用转发文章
+func QuotePost(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+	var input struct {
+		Content string `json:"content" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	quote, err := repostService.Quote(userID.(uint), uint(postID), input.Content)
+	if err != nil {
+		repostError(c, err)
+		return
+	}
+	common.Success(c, quote)
+}
+
+func repostError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "post not found":
+		common.Error(c, http.StatusNotFound, err.Error())
+	case "blocked", "cannot repost private post":
+		common.Error(c, http.StatusForbidden, err.Error())
+	case "content is required":
+		common.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		common.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
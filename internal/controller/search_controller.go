@@ -28,7 +28,7 @@ func (ctrl *SearchController) GlobalSearch(c *gin.Context) {
 	}
 
 	// 搜索文章 (前10条)
-	posts, _, err := ctrl.postService.GetPostList(1, 10, "", 0, "published", "created_at", keyword)
+	posts, _, err := ctrl.postService.GetPostList(1, 10, "", 0, 0, "published", "created_at", keyword, getActor(c))
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, "搜索文章失败")
 		return
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var seriesService = new(service.SeriesService)
+
+// CreateSeries 创建系列
+func CreateSeries(c *gin.Context) {
+	var input struct {
+		Title       string `json:"title" binding:"required,max=200"`
+		Description string `json:"description" binding:"max=1000"`
+		PostIDs     []uint `json:"post_ids"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	series, err := seriesService.CreateSeries(getActor(c), input.Title, input.Description, input.PostIDs)
+	if err != nil {
+		seriesError(c, err)
+		return
+	}
+	common.Success(c, series)
+}
+
+// GetSeries 获取系列详情及其中的文章
+func GetSeries(c *gin.Context) {
+	id, ok := seriesIDParam(c)
+	if !ok {
+		return
+	}
+
+	series, err := seriesService.GetSeries(id, getActor(c))
+	if err != nil {
+		seriesError(c, err)
+		return
+	}
+	common.Success(c, series)
+}
+
+// GetUserSeries 获取用户的系列列表
+func GetUserSeries(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	list, err := seriesService.ListUserSeries(userID)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, list)
+}
+
+// UpdateSeries 修改系列标题和简介
+func UpdateSeries(c *gin.Context) {
+	id, ok := seriesIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Title       string `json:"title" binding:"required,max=200"`
+		Description string `json:"description" binding:"max=1000"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := seriesService.UpdateSeries(id, getActor(c), input.Title, input.Description); err != nil {
+		seriesError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// SetSeriesPosts 设置系列中的文章及顺序
+func SetSeriesPosts(c *gin.Context) {
+	id, ok := seriesIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		PostIDs []uint `json:"post_ids"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := seriesService.SetSeriesPosts(id, getActor(c), input.PostIDs); err != nil {
+		seriesError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// DeleteSeries 删除系列
+func DeleteSeries(c *gin.Context) {
+	id, ok := seriesIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := seriesService.DeleteSeries(id, getActor(c)); err != nil {
+		seriesError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+func seriesIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid series ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func seriesError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "series not found":
+		common.Error(c, http.StatusNotFound, err.Error())
+	case "unauthorized":
+		common.Error(c, http.StatusForbidden, err.Error())
+	case "title is required", "too many posts in series", "duplicate post in series",
+		"post not found", "post already in another series":
+		common.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		common.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var sessionService = new(service.SessionService)
+
+// clientInfo 从请求中提取登录客户端信息
+func clientInfo(c *gin.Context) service.ClientInfo {
+	return service.ClientInfo{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// GetSessions 列出当前用户已登录的设备
+func GetSessions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	sessions, err := sessionService.ListSessions(userID.(uint), c.GetString("session_id"))
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, sessions)
+}
+
+// RevokeSession 注销指定设备上的登录
+func RevokeSession(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := sessionService.RevokeSession(userID.(uint), uint(id)); err != nil {
+		common.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
+
+// RevokeOtherSessions 退出除当前设备以外的所有登录
+func RevokeOtherSessions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if err := sessionService.RevokeOtherSessions(userID.(uint), c.GetString("session_id")); err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
+
+// Logout 退出当前登录
+func Logout(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	if err := sessionService.RevokeCurrentSession(userID.(uint), c.GetString("session_id")); err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
@@ -0,0 +1,33 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+var settingService = new(service.SettingService)
+
+// GetSettings 获取站点设置（仅管理员）
+func GetSettings(c *gin.Context) {
+	common.Success(c, settingService.All())
+}
+
+// UpdateSetting 修改站点设置（仅管理员）
+func UpdateSetting(c *gin.Context) {
+	var input struct {
+		Value string `json:"value"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := settingService.Set(c.Param("key"), input.Value, getActor(c)); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, nil)
+}
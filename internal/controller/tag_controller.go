@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"net/http"
+	"simple-blog/internal/common"
+	"simple-blog/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var tagService = new(service.TagService)
+
+// RenameTag 重命名标签（仅管理员）
+func RenameTag(c *gin.Context) {
+	id, ok := tagIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tag, err := tagService.RenameTag(id, getActor(c), input.Name)
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+	common.Success(c, tag)
+}
+
+// MergeTags 将其他标签合并到指定标签（仅管理员）
+func MergeTags(c *gin.Context) {
+	id, ok := tagIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		SourceIDs []uint `json:"source_ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := tagService.MergeTags(id, input.SourceIDs, getActor(c)); err != nil {
+		tagError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+// GetTagSynonyms 获取标签的同义词（仅管理员）
+func GetTagSynonyms(c *gin.Context) {
+	id, ok := tagIDParam(c)
+	if !ok {
+		return
+	}
+
+	synonyms, err := tagService.ListSynonyms(id)
+	if err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, synonyms)
+}
+
+// AddTagSynonym 为标签添加同义词（仅管理员）
+func AddTagSynonym(c *gin.Context) {
+	id, ok := tagIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	synonym, err := tagService.AddSynonym(id, getActor(c), input.Name)
+	if err != nil {
+		tagError(c, err)
+		return
+	}
+	common.Success(c, synonym)
+}
+
+// RemoveTagSynonym 删除标签的同义词（仅管理员）
+func RemoveTagSynonym(c *gin.Context) {
+	id, ok := tagIDParam(c)
+	if !ok {
+		return
+	}
+	synonymID, err := strconv.Atoi(c.Param("synonym_id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid synonym ID")
+		return
+	}
+
+	if err := tagService.RemoveSynonym(id, uint(synonymID), getActor(c)); err != nil {
+		tagError(c, err)
+		return
+	}
+	common.Success(c, nil)
+}
+
+func tagIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid tag ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func tagError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "unauthorized":
+		common.Error(c, http.StatusForbidden, err.Error())
+	case "tag not found", "synonym not found":
+		common.Error(c, http.StatusNotFound, err.Error())
+	case "tag already exists":
+		common.Error(c, http.StatusConflict, err.Error())
+	default:
+		common.Error(c, http.StatusBadRequest, err.Error())
+	}
+}
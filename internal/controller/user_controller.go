@@ -1,27 +1,34 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"simple-blog/internal/common"
 	"simple-blog/internal/config"
 	"simple-blog/internal/service"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 var userService = new(service.UserService)
+var loginGuardService = service.NewLoginGuardService()
 
 // Register 用户注册
 func Register(c *gin.Context) {
 	var input struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
-		Email    string `json:"email" binding:"required,email"`
+		Username      string `json:"username" binding:"required"`
+		Password      string `json:"password" binding:"required"`
+		Email         string `json:"email" binding:"required,email"`
+		InviteCode    string `json:"invite_code"`
+		CaptchaID     string `json:"captcha_id"`
+		CaptchaAnswer string `json:"captcha_answer"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -29,7 +36,8 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	if err := userService.Register(input.Username, input.Password, input.Email); err != nil {
+	if err := userService.Register(input.Username, input.Password, input.Email, input.InviteCode,
+		service.CaptchaAnswer{ID: input.CaptchaID, Answer: input.CaptchaAnswer}); err != nil {
 		common.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -40,8 +48,32 @@ func Register(c *gin.Context) {
 // Login 用户登录
 func Login(c *gin.Context) {
 	var input struct {
-		Username string `json:"username" binding:"required"`
-		Password string `json:"password" binding:"required"`
+		Username      string `json:"username" binding:"required"` // 用户名或邮箱
+		Password      string `json:"password" binding:"required"`
+		CaptchaID     string `json:"captcha_id"` // 失败次数较多后需要验证码
+		CaptchaAnswer string `json:"captcha_answer"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := userService.Login(input.Username, input.Password,
+		service.CaptchaAnswer{ID: input.CaptchaID, Answer: input.CaptchaAnswer}, clientInfo(c))
+	if err != nil {
+		loginError(c, err)
+		return
+	}
+
+	common.Success(c, result)
+}
+
+// LoginTwoFactor 两步验证登录的第二步
+func LoginTwoFactor(c *gin.Context) {
+	var input struct {
+		ChallengeToken string `json:"challenge_token" binding:"required"`
+		Code           string `json:"code" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -49,17 +81,55 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	token, userId, role, err := userService.Login(input.Username, input.Password)
+	result, err := userService.LoginTwoFactor(input.ChallengeToken, input.Code, clientInfo(c))
 	if err != nil {
-		common.Error(c, http.StatusUnauthorized, err.Error())
+		loginError(c, err)
+		return
+	}
+
+	common.Success(c, result)
+}
+
+// loginError 登录失败的响应，被锁定时返回 429 并带上 Retry-After
+func loginError(c *gin.Context, err error) {
+	var locked *service.LoginLockedError
+	if errors.As(err, &locked) {
+		c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())+1))
+		common.Error(c, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	common.Error(c, http.StatusUnauthorized, err.Error())
+}
+
+// UnlockLogin 解除用户或 IP 的登录锁定（仅管理员）
+func UnlockLogin(c *gin.Context) {
+	var input struct {
+		UserID uint   `json:"user_id"`
+		IP     string `json:"ip"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if input.UserID == 0 && input.IP == "" {
+		common.Error(c, http.StatusBadRequest, "user_id or ip is required")
 		return
 	}
 
-	common.Success(c, gin.H{
-		"token":   token,
-		"user_id": userId,
-		"role":    role,
-	})
+	actor := getActor(c)
+	if input.UserID != 0 {
+		if err := loginGuardService.UnlockUser(input.UserID, actor); err != nil {
+			common.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if input.IP != "" {
+		if err := loginGuardService.UnlockIP(input.IP, actor); err != nil {
+			common.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	common.Success(c, nil)
 }
 
 func FollowUser(c *gin.Context) {
@@ -72,11 +142,17 @@ func FollowUser(c *gin.Context) {
 
 	currentUserID, _ := c.Get("user_id")
 
-	if err := userService.FollowUser(currentUserID.(uint), uint(targetID)); err != nil {
+	pending, err := userService.FollowUser(currentUserID.(uint), uint(targetID))
+	if err != nil {
+		if err.Error() == "blocked" {
+			common.Error(c, http.StatusForbidden, "You cannot follow this user")
+			return
+		}
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
-	common.Success(c, nil)
+	// 关注私密账号时 pending 为 true，需等待对方批准
+	common.Success(c, gin.H{"pending": pending})
 }
 
 func UnfollowUser(c *gin.Context) {
@@ -97,14 +173,12 @@ func UnfollowUser(c *gin.Context) {
 }
 
 func GetFollowers(c *gin.Context) {
-	targetIDStr := c.Param("id")
-	targetID, err := strconv.Atoi(targetIDStr)
-	if err != nil {
-		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+	targetID, ok := userIDParam(c)
+	if !ok {
 		return
 	}
 
-	followers, err := userService.GetFollowers(uint(targetID))
+	followers, err := userService.GetFollowers(targetID)
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -113,14 +187,12 @@ func GetFollowers(c *gin.Context) {
 }
 
 func GetFollowing(c *gin.Context) {
-	targetIDStr := c.Param("id")
-	targetID, err := strconv.Atoi(targetIDStr)
-	if err != nil {
-		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+	targetID, ok := userIDParam(c)
+	if !ok {
 		return
 	}
 
-	following, err := userService.GetFollowing(uint(targetID))
+	following, err := userService.GetFollowing(targetID)
 	if err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -128,11 +200,39 @@ func GetFollowing(c *gin.Context) {
 	common.Success(c, following)
 }
 
-func GetUserProfile(c *gin.Context) {
-	targetIDStr := c.Param("id")
-	targetID, err := strconv.Atoi(targetIDStr)
+// userIDParam 解析路径中的用户，支持数字ID和 @用户名 两种形式。
+// 使用旧用户名访问时重定向到当前用户名对应的地址，解析失败时已写入错误响应
+func userIDParam(c *gin.Context) (uint, bool) {
+	param := c.Param("id")
+	if !strings.HasPrefix(param, "@") {
+		id, err := strconv.Atoi(param)
+		if err != nil {
+			common.Error(c, http.StatusBadRequest, "Invalid user ID")
+			return 0, false
+		}
+		return uint(id), true
+	}
+
+	name := strings.TrimPrefix(param, "@")
+	id, current, err := userService.ResolveUsername(name)
 	if err != nil {
-		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+		common.Error(c, http.StatusNotFound, "User not found")
+		return 0, false
+	}
+	if !strings.EqualFold(name, current) {
+		target := url.URL{
+			Path:     strings.Replace(c.Request.URL.Path, "/"+param, "/@"+current, 1),
+			RawQuery: c.Request.URL.RawQuery,
+		}
+		c.Redirect(http.StatusMovedPermanently, target.String())
+		return 0, false
+	}
+	return id, true
+}
+
+func GetUserProfile(c *gin.Context) {
+	targetID, ok := userIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -142,7 +242,7 @@ func GetUserProfile(c *gin.Context) {
 		currentUserID = val.(uint)
 	}
 
-	profile, err := userService.GetUserProfile(uint(targetID), currentUserID)
+	profile, err := userService.GetUserProfile(targetID, currentUserID)
 	if err != nil {
 		common.Error(c, http.StatusNotFound, "User not found")
 		return
@@ -156,6 +256,7 @@ func UpdateProfile(c *gin.Context) {
 		Avatar   string `json:"avatar"`
 		BlogName string `json:"blog_name"`
 		Bio      string `json:"bio"`
+		Locale   string `json:"locale" binding:"omitempty,oneof=zh en"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -164,12 +265,13 @@ func UpdateProfile(c *gin.Context) {
 	}
 
 	userID, _ := c.Get("user_id")
-	if err := userService.UpdateProfile(userID.(uint), input.Email, input.Avatar, input.BlogName, input.Bio); err != nil {
+	emailPending, err := userService.UpdateProfile(userID.(uint), input.Email, input.Avatar, input.BlogName, input.Bio, input.Locale)
+	if err != nil {
 		common.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	common.Success(c, nil)
+	common.Success(c, gin.H{"email_pending": emailPending})
 }
 
 func UploadAvatar(c *gin.Context) {
@@ -203,3 +305,70 @@ func UploadAvatar(c *gin.Context) {
 	avatarURL := "/" + filepath.ToSlash(filePath)
 	common.Success(c, gin.H{"avatar_url": avatarURL})
 }
+
+// UpdatePrivacy 切换私密账号
+func UpdatePrivacy(c *gin.Context) {
+	var input struct {
+		IsPrivate *bool `json:"is_private" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	if err := userService.SetPrivate(userID.(uint), *input.IsPrivate); err != nil {
+		common.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	common.Success(c, gin.H{"is_private": *input.IsPrivate})
+}
+
+// ChangeUsername 修改用户名
+func ChangeUsername(c *gin.Context) {
+	var input struct {
+		Username string `json:"username" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	userID, _ := c.Get("user_id")
+	username, err := userService.ChangeUsername(userID.(uint), input.Username)
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	common.Success(c, gin.H{"username": username})
+}
+
+// UpdateUserRole 修改用户角色（仅管理员）
+func UpdateUserRole(c *gin.Context) {
+	targetID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		common.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		common.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := userService.UpdateRole(uint(targetID), input.Role, getActor(c)); err != nil {
+		switch err.Error() {
+		case "unauthorized":
+			common.Error(c, http.StatusForbidden, err.Error())
+		case "user not found":
+			common.Error(c, http.StatusNotFound, err.Error())
+		default:
+			common.Error(c, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	common.Success(c, nil)
+}
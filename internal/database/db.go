@@ -38,7 +38,7 @@ func InitDB() {
 	sqlDB.SetConnMaxLifetime(10 * time.Second)
 
 	fmt.Println("MySQL started successfully.")
-	err = DB.AutoMigrate(&model.User{}, &model.Post{}, &model.Tag{}, &model.Comment{}, &model.Notification{})
+	err = DB.AutoMigrate(&model.User{}, &model.Post{}, &model.Tag{}, &model.Comment{}, &model.Notification{}, &model.AuditLog{}, &model.UserToken{}, &model.MailOutbox{}, &model.Setting{}, &model.RecoveryCode{}, &model.LoginAttempt{}, &model.PersonalAccessToken{}, &model.UserIdentity{}, &model.OAuthState{}, &model.UserSession{}, &model.DataExport{}, &model.Captcha{}, &model.InviteCode{}, &model.UsernameHistory{}, &model.PostSlugRedirect{}, &model.Series{}, &model.SeriesPost{}, &model.Category{}, &model.TagSynonym{}, &model.UserBlock{}, &model.FollowRequest{}, &model.Collection{}, &model.CollectionItem{}, &model.Reaction{}, &model.Mention{}, &model.Conversation{}, &model.ConversationMember{}, &model.Message{}, &model.RelatedPost{}, &model.UserRecommendation{}, &model.JobLock{})
 	if err != nil {
 		log.Fatal("数据库迁移失败: ", err)
 	}
@@ -0,0 +1,147 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"simple-blog/internal/config"
+	"strings"
+	"time"
+)
+
+// Message 一封待发送的邮件
+type Message struct {
+	To      []string
+	Subject string
+	Text    string // 纯文本正文
+	HTML    string // HTML 正文，可为空
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// New 根据配置的驱动创建 Mailer：smtp（默认）、log（输出到标准输出）、file（写入 .eml 文件）
+func New(cfg config.MailConfig) Mailer {
+	switch cfg.Driver {
+	case "log":
+		return &LogMailer{From: cfg.SMTP.From}
+	case "file":
+		return &FileMailer{Dir: cfg.FileDir, From: cfg.SMTP.From}
+	default:
+		return NewSMTPMailer(cfg.SMTP)
+	}
+}
+
+// SMTPMailer 通过 SMTP 服务器发送邮件
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPMailer(cfg config.SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{
+		Host:     cfg.Host,
+		Port:     cfg.Port,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		From:     cfg.From,
+	}
+}
+
+func (m *SMTPMailer) Send(msg *Message) error {
+	if m.Host == "" {
+		return fmt.Errorf("smtp host is not configured")
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mail has no recipients")
+	}
+
+	// 未配置用户名时不做认证，便于对接本地 SMTP 测试服务（如 MailHog）
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	body, err := buildMessage(m.From, msg)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(net.JoinHostPort(m.Host, m.Port), auth, m.From, msg.To, body)
+}
+
+// buildMessage 生成 MIME 格式的邮件内容，同时包含纯文本和 HTML 时使用 multipart/alternative
+func buildMessage(from string, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(k, v string) {
+		buf.WriteString(k + ": " + v + "\r\n")
+	}
+
+	header("From", from)
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, msg.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	boundary, err := randomBoundary()
+	if err != nil {
+		return nil, err
+	}
+	header("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	buf.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+	for _, p := range parts {
+		buf.WriteString("--" + boundary + "\r\n")
+		header("Content-Type", p.contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, p.content); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("--" + boundary + "--\r\n")
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(buf *bytes.Buffer, s string) error {
+	w := quotedprintable.NewWriter(buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func randomBoundary() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,117 @@
+package mailer
+
+import (
+	"bufio"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"strings"
+	"testing"
+)
+
+// smtpStandIn 进程内的最小 SMTP 服务，只接收一封邮件并把 DATA 内容发到 received
+type smtpStandIn struct {
+	listener net.Listener
+	received chan string
+}
+
+func startSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &smtpStandIn{listener: l, received: make(chan string, 1)}
+	t.Cleanup(func() { l.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) addr() (string, string) {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return host, port
+}
+
+func (s *smtpStandIn) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "MAIL FROM"), strings.HasPrefix(cmd, "RCPT TO"), cmd == "RSET", cmd == "NOOP":
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.received <- data.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailerSendsRenderedResetMail(t *testing.T) {
+	server := startSMTPStandIn(t)
+	host, port := server.addr()
+	m := &SMTPMailer{Host: host, Port: port, From: "no-reply@localhost"}
+
+	link := "http://localhost:5173/reset-password?token=" + strings.Repeat("ab", 32)
+	msg, err := Render("password_reset", "zh", map[string]interface{}{
+		"Username":      "alice",
+		"Link":          link,
+		"ExpireMinutes": 30,
+	})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	msg.To = []string{"alice@example.com"}
+	if err := m.Send(msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	raw := <-server.received
+	if !strings.Contains(raw, "To: alice@example.com") {
+		t.Fatalf("missing recipient header:\n%s", raw)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(raw)))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if !strings.Contains(string(body), link) {
+		t.Fatalf("reset link not found in mail body:\n%s", body)
+	}
+}
+
+func TestSMTPMailerRequiresHost(t *testing.T) {
+	m := &SMTPMailer{}
+	if err := m.Send(&Message{To: []string{"alice@example.com"}}); err == nil {
+		t.Fatal("expected error when smtp host is not configured")
+	}
+}
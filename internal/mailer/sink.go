@@ -0,0 +1,41 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer 将邮件内容打印到标准输出，适用于本地开发
+type LogMailer struct {
+	From string
+}
+
+func (m *LogMailer) Send(msg *Message) error {
+	fmt.Printf("[mail] from=%s to=%s subject=%q\n%s\n", m.From, strings.Join(msg.To, ","), msg.Subject, msg.Text)
+	return nil
+}
+
+// FileMailer 将每封邮件写入目录下的 .eml 文件，便于测试时检查
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(msg *Message) error {
+	if err := os.MkdirAll(m.Dir, os.ModePerm); err != nil {
+		return err
+	}
+	body, err := buildMessage(m.From, msg)
+	if err != nil {
+		return err
+	}
+	suffix, err := randomBoundary()
+	if err != nil {
+		return err
+	}
+	filename := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102150405"), suffix[:8])
+	return os.WriteFile(filepath.Join(m.Dir, filename), body, 0644)
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// DefaultLocale 未指定或不支持的语言时使用的模板语言
+const DefaultLocale = "zh"
+
+var supportedLocales = map[string]bool{"zh": true, "en": true}
+
+// Render 渲染指定语言的邮件模板。
+// templates/<locale>/<name>.txt 需定义 subject 和 text 两个模板，<name>.html 为可选的 HTML 正文
+func Render(name, locale string, data interface{}) (*Message, error) {
+	if !supportedLocales[locale] {
+		locale = DefaultLocale
+	}
+	base := fmt.Sprintf("templates/%s/%s", locale, name)
+
+	textTmpl, err := texttemplate.ParseFS(templateFS, base+".txt")
+	if err != nil {
+		return nil, err
+	}
+
+	var subject, text bytes.Buffer
+	if err := textTmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := textTmpl.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, err
+	}
+
+	msg := &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+	}
+
+	if _, err := templateFS.Open(base + ".html"); err == nil {
+		htmlTmpl, err := htmltemplate.ParseFS(templateFS, base+".html")
+		if err != nil {
+			return nil, err
+		}
+		var html bytes.Buffer
+		if err := htmlTmpl.Execute(&html, data); err != nil {
+			return nil, err
+		}
+		msg.HTML = html.String()
+	}
+	return msg, nil
+}
@@ -3,16 +3,27 @@ package middleware
 import (
 	"fmt"
 	"net/http"
-	"simple-blog/internal/config"
 	"simple-blog/internal/database"
 	"simple-blog/internal/model"
+	"simple-blog/internal/service"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
+var accessTokenService = new(service.AccessTokenService)
+
+// JWTAuth 只接受登录签发的 JWT
 func JWTAuth() gin.HandlerFunc {
+	return authenticate(false)
+}
+
+// TokenAuth 同时接受 JWT 和个人访问令牌，需配合 RequireScope 限定令牌可访问的接口
+func TokenAuth() gin.HandlerFunc {
+	return authenticate(true)
+}
+
+func authenticate(allowAccessToken bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -32,15 +43,31 @@ func JWTAuth() gin.HandlerFunc {
 
 		tokenString := parts[1]
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return config.JwtSecret, nil
-		})
+		// 个人访问令牌
+		if service.IsAccessToken(tokenString) {
+			if !allowAccessToken {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Access tokens are not allowed for this endpoint"})
+				c.Abort()
+				return
+			}
+			token, err := accessTokenService.Authenticate(tokenString, c.ClientIP())
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+			c.Set("token_scopes", token.ScopeList)
+			setUserContext(c, token.UserID)
+			c.Next()
+			return
+		}
 
-		if err != nil || !token.Valid {
+		userId, sessionID, err := service.ParseAccessToken(tokenString, c.ClientIP())
+		if err != nil {
 			fmt.Printf("JWTAuth: Token error: %v\n", err)
 			// 区分过期和其他错误
 			msg := "Invalid or expired token"
-			if err != nil && strings.Contains(err.Error(), "expired") {
+			if strings.Contains(err.Error(), "expired") {
 				msg = "token_expired"
 			}
 			c.JSON(http.StatusUnauthorized, gin.H{"error": msg})
@@ -49,24 +76,27 @@ func JWTAuth() gin.HandlerFunc {
 		}
 
 		//将UserID解析出来并存入上下文
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userIdFloat, ok := claims["user_id"].(float64); ok {
-				userId := uint(userIdFloat)
-				c.Set("user_id", userId)
-				c.Set("userID", userId)
-
-				// 获取用户角色
-				var user model.User
-				if err := database.DB.First(&user, userId).Error; err == nil {
-					c.Set("user_role", user.Role)
-					c.Set("role", user.Role) // Alias for compatibility
-				}
+		c.Set("session_id", sessionID)
+		setUserContext(c, userId)
+		c.Next()
+	}
+}
+
+// RequireScope 要求个人访问令牌具有指定权限范围，使用 JWT 登录的请求不受限制
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		val, isAccessToken := c.Get("token_scopes")
+		if !isAccessToken {
+			c.Next()
+			return
+		}
+		for _, s := range val.([]string) {
+			if s == scope {
 				c.Next()
 				return
 			}
 		}
-
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access token lacks required scope: " + scope})
 		c.Abort()
 	}
 }
@@ -81,28 +111,42 @@ func SoftJWTAuth() gin.HandlerFunc {
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) == 2 && parts[0] == "Bearer" {
-			tokenString := parts[1]
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				return config.JwtSecret, nil
-			})
-
-			if err == nil && token.Valid {
-				if claims, ok := token.Claims.(jwt.MapClaims); ok {
-					if userIdFloat, ok := claims["user_id"].(float64); ok {
-						userId := uint(userIdFloat)
-						c.Set("user_id", userId)
-						c.Set("userID", userId)
-
-						// 获取用户角色
-						var user model.User
-						if err := database.DB.First(&user, userId).Error; err == nil {
-							c.Set("user_role", user.Role)
-							c.Set("role", user.Role)
-						}
-					}
-				}
+			if userId, sessionID, err := service.ParseAccessToken(parts[1], c.ClientIP()); err == nil {
+				c.Set("session_id", sessionID)
+				setUserContext(c, userId)
 			}
 		}
 		c.Next()
 	}
 }
+
+// setUserContext 将用户ID和角色写入上下文
+func setUserContext(c *gin.Context, userId uint) {
+	c.Set("user_id", userId)
+	c.Set("userID", userId)
+
+	// 获取用户角色
+	var user model.User
+	if err := database.DB.First(&user, userId).Error; err == nil {
+		role := user.Role
+		// 站点要求管理员启用两步验证时，未启用的管理员只拥有普通用户权限
+		if service.TwoFactorSetupRequired(&user) {
+			role = "user"
+			c.Header("X-Two-Factor-Setup-Required", "true")
+		}
+		c.Set("user_role", role)
+		c.Set("role", role) // Alias for compatibility
+	}
+}
+
+// AdminOnly 要求当前用户为管理员，需在 JWTAuth 之后使用
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("user_role"); role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin privileges required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog 审计日志，仅追加，不允许修改或删除
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	ActorID    uint      `gorm:"index" json:"actor_id"`            // 操作人ID
+	Actor      User      `gorm:"foreignKey:ActorID" json:"actor"`  // 操作人
+	ActorRole  string    `gorm:"size:20" json:"actor_role"`        // 操作时的角色
+	Action     string    `gorm:"size:64;index" json:"action"`      // post.delete, comment.delete, user.role ...
+	TargetType string    `gorm:"size:32;index" json:"target_type"` // post, comment, user
+	TargetID   uint      `gorm:"index" json:"target_id"`           // 目标ID
+	Before     string    `gorm:"type:text" json:"before"`          // 操作前快照 (JSON)
+	After      string    `gorm:"type:text" json:"after"`           // 操作后快照 (JSON)
+	IP         string    `gorm:"size:64" json:"ip"`                // 请求来源 IP
+	UserAgent  string    `gorm:"size:255" json:"user_agent"`       // 请求 User-Agent
+}
+
+func (a *AuditLog) BeforeUpdate(tx *gorm.DB) error {
+	return errors.New("audit log is append-only")
+}
+
+func (a *AuditLog) BeforeDelete(tx *gorm.DB) error {
+	return errors.New("audit log is append-only")
+}
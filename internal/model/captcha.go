@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Captcha 图形验证码，校验一次后即删除
+type Captcha struct {
+	gorm.Model
+	TokenHash string    `gorm:"size:64;uniqueIndex"` // SHA-256(captcha_id)
+	Answer    string    `gorm:"size:16"`
+	ExpiresAt time.Time `gorm:"index"`
+}
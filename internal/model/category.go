@@ -0,0 +1,15 @@
+package model
+
+import "gorm.io/gorm"
+
+// Category 分类，由管理员维护的树形结构，每篇文章最多属于一个分类
+type Category struct {
+	gorm.Model
+	ParentID    *uint       `gorm:"index" json:"parent_id"`
+	Name        string      `gorm:"size:50" json:"name"`
+	Slug        string      `gorm:"size:100;uniqueIndex" json:"slug"`
+	Description string      `gorm:"size:500" json:"description"`
+	SortOrder   int         `json:"sort_order"`                  // 同级分类按此升序排列
+	PostCount   int64       `gorm:"-" json:"post_count"`         // 含子分类的已发布文章数
+	Children    []*Category `gorm:"-" json:"children,omitempty"` // 仅分类树接口填充
+}
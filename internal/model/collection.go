@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Collection 收藏夹，用户可以把收藏的文章分门别类，公开的收藏夹可以分享给他人
+type Collection struct {
+	gorm.Model
+	UserID      uint             `gorm:"index" json:"user_id"`
+	User        User             `json:"user"`
+	Name        string           `gorm:"size:100" json:"name"`
+	Description string           `gorm:"size:500" json:"description"`
+	IsPublic    bool             `json:"is_public"`
+	ItemCount   int              `gorm:"->;-:migration" json:"item_count"`
+	Items       []CollectionItem `json:"items,omitempty"`
+}
+
+// CollectionItem 收藏夹中的文章及用户为其写的备注，同一篇文章可以放入多个收藏夹
+type CollectionItem struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	CollectionID uint      `gorm:"uniqueIndex:idx_collection_post" json:"collection_id"`
+	PostID       uint      `gorm:"uniqueIndex:idx_collection_post;index" json:"post_id"`
+	Post         *Post     `json:"post,omitempty"`
+	Note         string    `gorm:"size:1000" json:"note"`
+	Position     int       `json:"position"`
+}
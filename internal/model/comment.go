@@ -13,4 +13,7 @@ type Comment struct {
 	ReplyToID   *uint     `json:"reply_to_id"`            // 被回复的评论ID（用于多级回复显示）
 	ReplyToUser *User     `json:"reply_to_user" gorm:"-"` // 仅用于前端显示，不直接关联
 	Replies     []Comment `json:"replies" gorm:"foreignKey:ParentID"`
+
+	Reactions []ReactionSummary `json:"reactions,omitempty" gorm:"-"` // 表情回应统计
+	Mentions  []MentionSpan     `json:"mentions,omitempty" gorm:"-"`  // 内容中的 @提及
 }
@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataExport 个人数据导出任务，由后台任务生成 ZIP 文件
+type DataExport struct {
+	gorm.Model
+	UserID      uint       `gorm:"index" json:"user_id"`
+	Status      string     `gorm:"size:20;index;default:'pending'" json:"status"` // pending, processing, ready, failed, expired
+	FilePath    string     `gorm:"size:255" json:"-"`
+	FileSize    int64      `json:"file_size"`
+	Error       string     `gorm:"size:1000" json:"error,omitempty"`
+	StartedAt   *time.Time `json:"-"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at"` // 过期后文件会被清理
+}
@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// FollowRequest 关注私密账号时的待处理请求，通过后转为关注关系并删除请求
+type FollowRequest struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	FollowerID uint      `gorm:"uniqueIndex:idx_follow_request" json:"follower_id"`
+	Follower   User      `gorm:"foreignKey:FollowerID" json:"follower"`
+	FollowedID uint      `gorm:"uniqueIndex:idx_follow_request;index" json:"followed_id"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InviteCode 邀请码，站点为邀请注册模式时注册需要使用
+type InviteCode struct {
+	gorm.Model
+	Code      string     `gorm:"size:32;uniqueIndex" json:"code"`
+	CreatedBy uint       `gorm:"index" json:"created_by"`
+	MaxUses   int        `json:"max_uses"`
+	UsedCount int        `json:"used_count"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Note      string     `gorm:"size:255" json:"note"`
+}
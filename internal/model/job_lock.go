@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// JobLock 后台任务的锁，多实例部署时 LockedUntil 之前同一任务只由持有锁的实例执行
+type JobLock struct {
+	Name        string    `gorm:"primarykey;size:64" json:"name"`
+	LockedUntil time.Time `json:"locked_until"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
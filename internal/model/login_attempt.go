@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// LoginAttempt 登录失败计数，按用户名或 IP 分别记录
+type LoginAttempt struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	AttemptKey   string    `gorm:"size:191;uniqueIndex" json:"attempt_key"` // user:<username> 或 ip:<ip>
+	Failures     int       `gorm:"default:0" json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	LockedUntil  time.Time `json:"locked_until"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MailOutbox 待发送邮件队列，由后台任务投递
+type MailOutbox struct {
+	gorm.Model
+	To            string     `gorm:"size:255;not null" json:"to"`
+	Template      string     `gorm:"size:64" json:"template"`
+	Subject       string     `gorm:"size:255" json:"subject"`
+	Text          string     `gorm:"type:text" json:"-"` // 正文可能含有重置密码等一次性链接，发送成功后清空，不对外返回
+	HTML          string     `gorm:"type:text" json:"-"`
+	Status        string     `gorm:"size:20;index;default:'pending'" json:"status"` // pending, sending, sent, failed
+	Attempts      int        `gorm:"default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index" json:"next_attempt_at"` // 下次尝试时间；sending 状态下为租约到期时间
+	LastError     string     `gorm:"size:1000" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at"`
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// Mention 文章或评论中对用户的 @提及，同一内容中多次提及同一用户只记一条
+type Mention struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UserID     uint      `gorm:"uniqueIndex:idx_mention" json:"user_id"`                                      // 被提及的用户
+	SourceType string    `gorm:"size:20;uniqueIndex:idx_mention;index:idx_mention_source" json:"source_type"` // post, comment
+	SourceID   uint      `gorm:"uniqueIndex:idx_mention;index:idx_mention_source" json:"source_id"`
+	PostID     uint      `json:"post_id"` // 评论中的提及为评论所属的文章
+	AuthorID   uint      `gorm:"index" json:"author_id"`
+	Author     User      `gorm:"foreignKey:AuthorID" json:"author"`
+}
+
+// MentionSpan 内容中一处能解析到用户的提及，Start 和 End 为 @ 起始和结尾的字符位置（按 Unicode 码点计算，不含 End）
+type MentionSpan struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+}
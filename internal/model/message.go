@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// Conversation 两个用户之间的私信会话，UserAID 为较小的用户ID，保证同一对用户只有一个会话
+type Conversation struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time `json:"created_at"`
+	UserAID       uint      `gorm:"uniqueIndex:idx_conversation_pair" json:"-"`
+	UserBID       uint      `gorm:"uniqueIndex:idx_conversation_pair;index" json:"-"`
+	LastMessageID uint      `json:"-"`
+	LastMessageAt time.Time `gorm:"index" json:"last_message_at"`
+
+	Peer           *User    `gorm:"-" json:"peer,omitempty"`         // 会话的另一方
+	LastMessage    *Message `gorm:"-" json:"last_message,omitempty"` // 最近一条私信
+	UnreadCount    int64    `gorm:"-" json:"unread_count"`
+	Muted          bool     `gorm:"-" json:"muted"`
+	PeerLastReadID uint     `gorm:"-" json:"peer_last_read_id"` // 对方已读到的私信ID，用于显示已读状态
+}
+
+// ConversationMember 用户在会话中的状态：已读位置和是否静音
+type ConversationMember struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time `json:"created_at"`
+	ConversationID uint      `gorm:"uniqueIndex:idx_conversation_member" json:"conversation_id"`
+	UserID         uint      `gorm:"uniqueIndex:idx_conversation_member;index" json:"user_id"`
+	LastReadID     uint      `json:"last_read_id"` // 已读到的最后一条私信ID
+	Muted          bool      `gorm:"default:false" json:"muted"`
+}
+
+// Message 私信
+type Message struct {
+	ID             uint      `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time `gorm:"index" json:"created_at"`
+	ConversationID uint      `gorm:"index" json:"conversation_id"`
+	SenderID       uint      `json:"sender_id"`
+	Content        string    `gorm:"type:text" json:"content"`
+	Read           bool      `gorm:"-" json:"read"` // 对方是否已读
+}
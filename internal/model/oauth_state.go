@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// OAuthState 第三方登录进行中的授权请求，回调时校验并删除
+type OAuthState struct {
+	ID           uint      `gorm:"primarykey"`
+	StateHash    string    `gorm:"size:64;uniqueIndex"`
+	Provider     string    `gorm:"size:32"`
+	CodeVerifier string    `gorm:"size:128"` // PKCE code_verifier
+	Nonce        string    `gorm:"size:128"`
+	LinkUserID   uint      // 非 0 表示为已登录用户绑定第三方账号
+	ExpiresAt    time.Time `gorm:"index"`
+	CreatedAt    time.Time
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken 个人访问令牌，供脚本和 CI 调用 API，只保存哈希值
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID     uint       `gorm:"index" json:"user_id"`
+	Name       string     `gorm:"size:100" json:"name"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex" json:"-"`
+	Prefix     string     `gorm:"size:16" json:"prefix"` // 令牌前几位，便于用户辨认
+	Scopes     string     `gorm:"size:255" json:"-"`     // 逗号分隔的权限范围
+	ScopeList  []string   `gorm:"-" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	LastUsedIP string     `gorm:"size:64" json:"last_used_ip"`
+}
+
+func (t *PersonalAccessToken) AfterFind(tx *gorm.DB) error {
+	t.ScopeList = nil
+	if t.Scopes != "" {
+		t.ScopeList = strings.Split(t.Scopes, ",")
+	}
+	return nil
+}
@@ -9,7 +9,8 @@ type Post struct {
 	gorm.Model
 	Title         string    `json:"title" binding:"required"`
 	Content       string    `json:"content" binding:"required"`
-	UserID        uint      `json:"user_id"` //uid
+	UserID        uint      `json:"user_id" gorm:"uniqueIndex:idx_post_user_slug"`       //uid
+	Slug          *string   `json:"slug" gorm:"size:100;uniqueIndex:idx_post_user_slug"` // 作者内唯一的 URL 标识
 	User          User      `json:"user"`
 	Tags          []Tag     `json:"tags" gorm:"many2many:post_tags;"`
 	TagNames      []string  `json:"tag_names" gorm:"-"` // 用于接收前端传来的标签名列表
@@ -23,4 +24,21 @@ type Post struct {
 	IsTop         bool      `json:"is_top" gorm:"default:false"`                        // 个人主页置顶
 	IsSystemTop   bool      `json:"is_system_top" gorm:"default:false"`                 // 全站首页置顶（仅管理员）
 	ViewCount     int       `json:"view_count" gorm:"default:0"`
+
+	Series *PostSeriesInfo `json:"series,omitempty" gorm:"-"` // 所属系列，仅详情接口填充
+
+	CategoryID *uint     `json:"category_id" gorm:"index"`
+	Category   *Category `json:"category,omitempty"`
+
+	Reactions []ReactionSummary `json:"reactions,omitempty" gorm:"-"` // 表情回应统计，仅列表和详情接口填充
+	Mentions  []MentionSpan     `json:"mentions,omitempty" gorm:"-"`  // 内容中的 @提及
+
+	// 转发的原文。内容为空时为直接转发，否则为带评论的引用转发
+	RepostOfID        *uint `json:"repost_of_id" gorm:"index"`
+	RepostOf          *Post `json:"repost_of,omitempty" gorm:"-"`
+	RepostUnavailable bool  `json:"repost_unavailable,omitempty" gorm:"-"` // 原文已删除、隐藏或当前用户无权查看
+	RepostCount       int   `json:"repost_count" gorm:"->;-:migration"`
+	Reposted          bool  `json:"reposted" gorm:"-"` // 当前用户是否已直接转发
+
+	HotScore float64 `json:"hot_score" gorm:"default:0;index"` // 热度，由后台任务定期计算
 }
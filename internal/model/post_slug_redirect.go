@@ -0,0 +1,11 @@
+package model
+
+import "gorm.io/gorm"
+
+// PostSlugRedirect 文章修改 slug 后保留的旧 slug，访问旧地址时跳转到新地址
+type PostSlugRedirect struct {
+	gorm.Model
+	UserID uint   `gorm:"uniqueIndex:idx_slug_redirect_user_slug" json:"user_id"`
+	Slug   string `gorm:"size:100;uniqueIndex:idx_slug_redirect_user_slug" json:"slug"`
+	PostID uint   `gorm:"index" json:"post_id"`
+}
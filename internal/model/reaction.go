@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Reaction 用户对文章或评论的表情回应，同一用户对同一对象的同一表情只有一条
+type Reaction struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UserID     uint      `gorm:"uniqueIndex:idx_reaction" json:"user_id"`
+	TargetType string    `gorm:"size:20;uniqueIndex:idx_reaction;index:idx_reaction_target" json:"target_type"` // post, comment
+	TargetID   uint      `gorm:"uniqueIndex:idx_reaction;index:idx_reaction_target" json:"target_id"`
+	Emoji      string    `gorm:"type:varchar(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin;uniqueIndex:idx_reaction" json:"emoji"` // 区分不同表情，不能使用默认排序规则
+}
+
+// ReactionSummary 某个表情的回应数量，Reacted 表示当前用户是否使用了该表情
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int64  `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
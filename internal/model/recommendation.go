@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// RelatedPost 后台任务计算的相关文章，Position 从 1 开始，每次计算时整体替换
+type RelatedPost struct {
+	ID        uint      `gorm:"primarykey" json:"-"`
+	PostID    uint      `gorm:"index" json:"post_id"`
+	RelatedID uint      `json:"related_id"`
+	Score     float64   `json:"score"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserRecommendation 后台任务计算的推荐关注用户
+type UserRecommendation struct {
+	ID          uint      `gorm:"primarykey" json:"-"`
+	UserID      uint      `gorm:"index" json:"-"`
+	CandidateID uint      `gorm:"index" json:"candidate_id"`
+	Candidate   User      `gorm:"foreignKey:CandidateID" json:"user"`
+	Score       float64   `json:"score"`
+	Reason      string    `gorm:"size:20" json:"reason"` // co_follow: 关注的人也关注了 TA；co_like: 喜欢相同文章的人也喜欢 TA 的文章；popular: 热门作者
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCode 两步验证的一次性恢复码，只保存哈希值
+type RecoveryCode struct {
+	gorm.Model
+	UserID   uint       `gorm:"index" json:"user_id"`
+	CodeHash string     `gorm:"size:64;index" json:"-"`
+	UsedAt   *time.Time `json:"used_at"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "gorm.io/gorm"
+
+// Series 系列，作者将多篇文章按顺序组织在一起（如多篇连载教程）
+type Series struct {
+	gorm.Model
+	UserID      uint   `gorm:"index" json:"user_id"`
+	User        User   `json:"user"`
+	Title       string `gorm:"size:200" json:"title"`
+	Description string `gorm:"size:1000" json:"description"`
+	PostCount   int    `gorm:"->;-:migration" json:"post_count"`
+	Posts       []Post `gorm:"-" json:"posts,omitempty"`
+}
+
+// SeriesPost 系列中的文章及其顺序，一篇文章最多属于一个系列
+type SeriesPost struct {
+	ID       uint `gorm:"primarykey" json:"-"`
+	SeriesID uint `gorm:"index" json:"series_id"`
+	PostID   uint `gorm:"uniqueIndex" json:"post_id"`
+	Position int  `json:"position"`
+}
+
+// PostSeriesInfo 文章详情中附带的系列信息，Position 从 1 开始
+type PostSeriesInfo struct {
+	ID       uint           `json:"id"`
+	Title    string         `json:"title"`
+	Position int            `json:"position"`
+	Total    int            `json:"total"`
+	Prev     *SeriesPostRef `json:"prev"`
+	Next     *SeriesPostRef `json:"next"`
+}
+
+// SeriesPostRef 系列中相邻文章的简要信息
+type SeriesPostRef struct {
+	ID    uint    `json:"id"`
+	Title string  `json:"title"`
+	Slug  *string `json:"slug"`
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Setting 站点设置（键值对），由管理员在后台修改
+type Setting struct {
+	Key       string    `gorm:"primarykey;size:64" json:"key"`
+	Value     string    `gorm:"type:text" json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -4,5 +4,22 @@ import "gorm.io/gorm"
 
 type Tag struct {
 	gorm.Model
-	Name string `json:"name" gorm:"type:varchar(100);uniqueIndex"`
+	Name           string  `json:"name" gorm:"type:varchar(100);uniqueIndex"`
+	NormalizedName *string `json:"-" gorm:"size:100;uniqueIndex"`              // 统一大小写和全半角后的名称，用于查重
+	PostCount      int64   `json:"post_count,omitempty" gorm:"->;-:migration"` // 使用次数，仅标签列表查询时填充
+}
+
+// TagSynonym 标签同义词，作者填写同义词时自动归入对应标签
+type TagSynonym struct {
+	gorm.Model
+	Name  string `gorm:"size:100;uniqueIndex" json:"name"` // 规范化后的名称
+	TagID uint   `gorm:"index" json:"tag_id"`
+}
+
+// TrendingTag 近期使用次数增长最快的标签，Uses 和 Previous 分别为当前和上一个时间窗口内的使用次数
+type TrendingTag struct {
+	Tag      Tag     `json:"tag"`
+	Uses     int64   `json:"uses"`
+	Previous int64   `json:"previous"`
+	Growth   float64 `json:"growth"`
 }
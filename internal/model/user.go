@@ -1,20 +1,43 @@
 package model
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
 	Username      string    `gorm:"uniqueIndex;not null;size:255" json:"username"`
 	Password      string    `gorm:"not null" json:"-"` // 存储哈希后的密码
 	Email         string    `gorm:"uniqueIndex;size:255" json:"email"`
+	EmailVerified bool      `gorm:"default:false" json:"email_verified"`
 	Avatar        string    `json:"avatar"`
 	BlogName      string    `gorm:"size:100" json:"blog_name"`
 	Bio           string    `gorm:"size:500" json:"bio"`
-	Role          string    `gorm:"default:'user'" json:"role"` // user or admin
+	Role          string    `gorm:"default:'user'" json:"role"`         // user or admin
+	Locale        string    `gorm:"size:10;default:'zh'" json:"locale"` // 邮件等通知使用的语言：zh, en
+	TwoFAEnabled  bool      `gorm:"default:false" json:"two_fa_enabled"`
+	TwoFASecret   string    `gorm:"size:64" json:"-"` // TOTP 密钥，启用前为待确认的密钥
+	TwoFALastStep int64     `json:"-"`                // 最近一次成功使用的时间窗口，防止验证码重放
 	Posts         []Post    `json:"posts,omitempty"`
 	Comments      []Comment `json:"comments,omitempty"`
 	Followers     []*User   `gorm:"many2many:user_followers;joinForeignKey:followed_id;joinReferences:follower_id" json:"-"`
 	Following     []*User   `gorm:"many2many:user_followers;joinForeignKey:follower_id;joinReferences:followed_id" json:"-"`
 	LikedPosts    []*Post   `gorm:"many2many:user_likes;" json:"liked_posts,omitempty"`
 	FavoritePosts []*Post   `gorm:"many2many:user_favorites;" json:"favorite_posts,omitempty"`
+
+	// 申请注销账号后的计划执行时间，冷静期内可以撤销
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+	DeletionMode        string     `gorm:"size:20" json:"deletion_mode,omitempty"` // anonymize: 保留内容并匿名化；delete: 同时删除内容
+
+	InviteCodeID *uint `json:"-"` // 注册时使用的邀请码
+
+	FollowedTags []*Tag `gorm:"many2many:user_tag_follows;" json:"-"` // 关注的标签，用于个人时间线
+
+	IsPrivate bool `gorm:"default:false" json:"is_private"` // 私密账号：关注需要批准，文章仅关注者可见
+
+	MentionNotify string `gorm:"size:20;default:'everyone'" json:"mention_notify"` // 接收哪些人的 @提及通知：everyone, following, none
+
+	MessageFrom string `gorm:"size:20;default:'everyone'" json:"message_from"` // 允许哪些人发私信：everyone, following（仅自己关注的人）
 }
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// UserBlock 用户对其他用户的屏蔽或静音。
+// 屏蔽（block）阻止对方关注、评论、回复和发送通知，并隐藏对方的内容；静音（mute）只在时间线中隐藏对方
+type UserBlock struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UserID    uint      `gorm:"uniqueIndex:idx_user_block" json:"user_id"`
+	BlockedID uint      `gorm:"uniqueIndex:idx_user_block;index" json:"blocked_id"`
+	Blocked   User      `gorm:"foreignKey:BlockedID" json:"blocked"`
+	Kind      string    `gorm:"size:10;uniqueIndex:idx_user_block" json:"kind"` // block, mute
+}
@@ -0,0 +1,13 @@
+package model
+
+import "gorm.io/gorm"
+
+// UserIdentity 关联到本站用户的第三方账号
+type UserIdentity struct {
+	gorm.Model
+	UserID   uint   `gorm:"index" json:"user_id"`
+	Provider string `gorm:"size:32;uniqueIndex:idx_provider_subject" json:"provider"`
+	Subject  string `gorm:"size:191;uniqueIndex:idx_provider_subject" json:"subject"` // 提供方的用户唯一标识
+	Username string `gorm:"size:255" json:"username"`
+	Email    string `gorm:"size:255" json:"email"`
+}
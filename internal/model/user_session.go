@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserSession 登录会话，每次登录创建一条，JWT 中的 sid 指向该记录；删除即代表会话被注销
+type UserSession struct {
+	gorm.Model
+	UserID     uint      `gorm:"index" json:"user_id"`
+	SessionID  string    `gorm:"size:64;uniqueIndex" json:"-"`
+	UserAgent  string    `gorm:"size:255" json:"user_agent"`
+	IP         string    `gorm:"size:64" json:"ip"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `gorm:"index" json:"expires_at"`
+	IsCurrent  bool      `gorm:"-" json:"is_current"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserToken 一次性令牌（密码重置、邮箱验证），只保存哈希值
+type UserToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"index" json:"user_id"`
+	Purpose   string     `gorm:"size:32;index" json:"purpose"` // password_reset, email_verify
+	TokenHash string     `gorm:"size:64;uniqueIndex" json:"-"` // SHA-256(token)
+	Email     string     `gorm:"size:255" json:"email"`        // 邮箱验证时待确认的邮箱
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
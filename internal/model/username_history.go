@@ -0,0 +1,10 @@
+package model
+
+import "gorm.io/gorm"
+
+// UsernameHistory 用户名修改记录，用于旧用户名跳转以及保留近期释放的用户名
+type UsernameHistory struct {
+	gorm.Model
+	UserID      uint   `gorm:"index" json:"user_id"`
+	OldUsername string `gorm:"size:255;index" json:"old_username"`
+}
@@ -0,0 +1,288 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"simple-blog/internal/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Identity 第三方账号信息
+type Identity struct {
+	Subject       string
+	Username      string
+	Name          string
+	Email         string
+	EmailVerified bool
+	Avatar        string
+}
+
+// Provider 一个 OAuth2 / OpenID Connect 提供方
+type Provider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Issuer 非空时按 OIDC 工作：端点来自发现文档，身份来自经过校验的 ID Token
+	Issuer string
+
+	// 纯 OAuth2 提供方的端点，身份来自 UserInfo 接口
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+
+	client *http.Client
+
+	mu           sync.Mutex
+	discovery    *discoveryDocument
+	discoveredAt time.Time
+	keys         map[string]interface{}
+	keysAt       time.Time
+}
+
+// 内置的纯 OAuth2 提供方端点
+var presets = map[string]config.OAuthProviderConfig{
+	"github": {
+		AuthURL:     "https://github.com/login/oauth/authorize",
+		TokenURL:    "https://github.com/login/oauth/access_token",
+		UserInfoURL: "https://api.github.com/user",
+		Scopes:      "read:user user:email",
+	},
+	"gitee": {
+		AuthURL:     "https://gitee.com/oauth/authorize",
+		TokenURL:    "https://gitee.com/oauth/token",
+		UserInfoURL: "https://gitee.com/api/v5/user",
+		Scopes:      "user_info emails",
+	},
+}
+
+// 发现文档和签名公钥的缓存时长
+const metadataCacheTTL = time.Hour
+
+func NewProvider(cfg config.OAuthProviderConfig) *Provider {
+	if preset, ok := presets[cfg.Name]; ok && cfg.Issuer == "" {
+		if cfg.AuthURL == "" {
+			cfg.AuthURL = preset.AuthURL
+		}
+		if cfg.TokenURL == "" {
+			cfg.TokenURL = preset.TokenURL
+		}
+		if cfg.UserInfoURL == "" {
+			cfg.UserInfoURL = preset.UserInfoURL
+		}
+		if cfg.Scopes == "" {
+			cfg.Scopes = preset.Scopes
+		}
+	}
+	if cfg.Scopes == "" && cfg.Issuer != "" {
+		cfg.Scopes = "openid profile email"
+	}
+
+	return &Provider{
+		Name:         cfg.Name,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       strings.Fields(cfg.Scopes),
+		Issuer:       strings.TrimSuffix(cfg.Issuer, "/"),
+		AuthURL:      cfg.AuthURL,
+		TokenURL:     cfg.TokenURL,
+		UserInfoURL:  cfg.UserInfoURL,
+		client:       &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *Provider) IsOIDC() bool {
+	return p.Issuer != ""
+}
+
+// AuthCodeURL 生成授权地址（授权码模式 + PKCE S256）
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	authURL := p.AuthURL
+	if p.IsOIDC() {
+		doc, err := p.getDiscovery(ctx)
+		if err != nil {
+			return "", err
+		}
+		authURL = doc.AuthorizationEndpoint
+	}
+	if authURL == "" {
+		return "", errors.New("authorization endpoint is not configured")
+	}
+
+	v := url.Values{}
+	v.Set("response_type", "code")
+	v.Set("client_id", p.ClientID)
+	v.Set("redirect_uri", p.RedirectURL)
+	v.Set("scope", strings.Join(p.Scopes, " "))
+	v.Set("state", state)
+	v.Set("code_challenge", PKCEChallenge(verifier))
+	v.Set("code_challenge_method", "S256")
+	if p.IsOIDC() {
+		v.Set("nonce", nonce)
+	}
+
+	sep := "?"
+	if strings.Contains(authURL, "?") {
+		sep = "&"
+	}
+	return authURL + sep + v.Encode(), nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	TokenType        string `json:"token_type"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange 用授权码换取令牌并解析出第三方账号信息
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	tokenURL := p.TokenURL
+	if p.IsOIDC() {
+		doc, err := p.getDiscovery(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tokenURL = doc.TokenEndpoint
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.RedirectURL)
+	form.Set("client_id", p.ClientID)
+	form.Set("client_secret", p.ClientSecret)
+	form.Set("code_verifier", verifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	if err := p.doJSON(req, &token); err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("token exchange failed: %s %s", token.Error, token.ErrorDescription)
+	}
+
+	if p.IsOIDC() {
+		if token.IDToken == "" {
+			return nil, errors.New("id_token missing from token response")
+		}
+		return p.verifyIDToken(ctx, token.IDToken, nonce)
+	}
+
+	if token.AccessToken == "" {
+		return nil, errors.New("access_token missing from token response")
+	}
+	return p.fetchUserInfo(ctx, token.AccessToken)
+}
+
+// fetchUserInfo 从纯 OAuth2 提供方的用户接口读取账号信息，兼容 GitHub/Gitee 与 OIDC 风格的字段
+func (p *Provider) fetchUserInfo(ctx context.Context, accessToken string) (*Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.UserInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info map[string]interface{}
+	if err := p.doJSON(req, &info); err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+
+	identity := &Identity{
+		Subject:       claimString(info, "sub", "id"),
+		Username:      claimString(info, "preferred_username", "login"),
+		Name:          claimString(info, "name"),
+		Email:         claimString(info, "email"),
+		EmailVerified: claimBool(info, "email_verified"),
+		Avatar:        claimString(info, "picture", "avatar_url"),
+	}
+	if identity.Subject == "" {
+		return nil, errors.New("userinfo response has no subject")
+	}
+	return identity, nil
+}
+
+func (p *Provider) doJSON(req *http.Request, out interface{}) error {
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, out)
+}
+
+// NewPKCEVerifier 生成 PKCE code_verifier
+func NewPKCEVerifier() (string, error) {
+	return RandomString(32)
+}
+
+// PKCEChallenge 计算 S256 方式的 code_challenge
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// RandomString 生成 URL 安全的随机字符串，用于 state、nonce 等
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func claimString(claims map[string]interface{}, keys ...string) string {
+	for _, key := range keys {
+		switch v := claims[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return fmt.Sprintf("%.0f", v)
+		case json.Number:
+			return v.String()
+		}
+	}
+	return ""
+}
+
+func claimBool(claims map[string]interface{}, key string) bool {
+	switch v := claims[key].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"context"
+	"simple-blog/internal/config"
+	"simple-blog/internal/oauth/oauthtest"
+	"testing"
+)
+
+func newMockProvider(t *testing.T) (*oauthtest.Server, *Provider) {
+	t.Helper()
+	server, err := oauthtest.NewServer("blog", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	provider := NewProvider(config.OAuthProviderConfig{
+		Name:         "mock",
+		ClientID:     "blog",
+		ClientSecret: "secret",
+		Issuer:       server.URL,
+		RedirectURL:  "http://localhost:5173/oauth/callback/mock",
+	})
+	return server, provider
+}
+
+// authorize 生成授权地址并模拟用户在 IdP 登录，返回授权码
+func authorize(t *testing.T, server *oauthtest.Server, provider *Provider, state, nonce, verifier string) string {
+	t.Helper()
+	authURL, err := provider.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("auth url: %v", err)
+	}
+	code, gotState, err := server.Authorize(authURL, oauthtest.User{
+		Subject:       "user-1",
+		Username:      "alice",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if gotState != state {
+		t.Fatalf("state = %q, want %q", gotState, state)
+	}
+	return code
+}
+
+func TestOIDCExchange(t *testing.T) {
+	server, provider := newMockProvider(t)
+	verifier, _ := NewPKCEVerifier()
+	code := authorize(t, server, provider, "state-1", "nonce-1", verifier)
+
+	identity, err := provider.Exchange(context.Background(), code, verifier, "nonce-1")
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+	if identity.Subject != "user-1" || identity.Username != "alice" || identity.Email != "alice@example.com" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity: %+v", identity)
+	}
+
+	if _, err := provider.Exchange(context.Background(), code, verifier, "nonce-1"); err == nil {
+		t.Fatal("expected reused authorization code to be rejected")
+	}
+}
+
+func TestOIDCExchangeRejectsNonceMismatch(t *testing.T) {
+	server, provider := newMockProvider(t)
+	verifier, _ := NewPKCEVerifier()
+	code := authorize(t, server, provider, "state-1", "nonce-1", verifier)
+
+	if _, err := provider.Exchange(context.Background(), code, verifier, "other-nonce"); err == nil {
+		t.Fatal("expected nonce mismatch to be rejected")
+	}
+}
+
+func TestOIDCExchangeRejectsWrongVerifier(t *testing.T) {
+	server, provider := newMockProvider(t)
+	verifier, _ := NewPKCEVerifier()
+	code := authorize(t, server, provider, "state-1", "nonce-1", verifier)
+
+	other, _ := NewPKCEVerifier()
+	if _, err := provider.Exchange(context.Background(), code, other, "nonce-1"); err == nil {
+		t.Fatal("expected wrong PKCE verifier to be rejected")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"simple-blog/internal/config"
 	"simple-blog/internal/controller"
 	"simple-blog/internal/middleware"
+	"simple-blog/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,58 +24,198 @@ func SetupRouter() *gin.Engine {
 	v1 := r.Group("/api/v1")
 	{
 		// 公开路由
+		v1.GET("/captcha", controller.GetCaptcha)
+		v1.GET("/registration", controller.GetRegistrationMode)
 		v1.POST("/register", controller.Register)
 		v1.POST("/login", controller.Login)
+		v1.POST("/login/2fa", controller.LoginTwoFactor)
+		v1.POST("/password/forgot", controller.ForgotPassword)
+		v1.POST("/password/reset", controller.ResetPassword)
+		v1.POST("/email/verify", controller.VerifyEmail)
+		v1.GET("/oauth/providers", controller.GetOAuthProviders)
+		v1.GET("/oauth/:provider/authorize", controller.OAuthAuthorize)
+		v1.POST("/oauth/:provider/callback", middleware.SoftJWTAuth(), controller.OAuthCallback)
 		v1.GET("/posts", middleware.SoftJWTAuth(), controller.GetPostList)
 		v1.GET("/posts/hot", controller.GetHotPosts)
-		v1.GET("/posts/:id", controller.GetPostDetail)
+		v1.GET("/posts/:id", middleware.SoftJWTAuth(), controller.GetPostDetail)
+		v1.GET("/u/:username/posts/:slug", middleware.SoftJWTAuth(), controller.GetPostBySlug)
+		v1.GET("/series/:id", middleware.SoftJWTAuth(), controller.GetSeries)
 		v1.GET("/tags", controller.GetTags)
+		v1.GET("/tags/trending", controller.GetTrendingTags)
+		v1.GET("/categories", controller.GetCategories)
 		searchCtrl := controller.NewSearchController()
-		v1.GET("/search", searchCtrl.GlobalSearch)
+		v1.GET("/search", middleware.SoftJWTAuth(), searchCtrl.GlobalSearch)
 
 		// Comments (Public Read)
-		v1.GET("/posts/:id/comments", controller.GetComments)
+		v1.GET("/posts/:id/comments", middleware.SoftJWTAuth(), controller.GetComments)
 		v1.GET("/posts/:id/like", middleware.SoftJWTAuth(), controller.GetPostLikeStatus)
+		v1.GET("/posts/:id/related", middleware.SoftJWTAuth(), controller.GetRelatedPosts)
+		v1.GET("/reactions/emojis", controller.GetReactionEmojis)
 
 		// User Profile & Relations (Public Read)
 		v1.GET("/users/:id/followers", controller.GetFollowers)
 		v1.GET("/users/:id/following", controller.GetFollowing)
-		v1.GET("/users/:id/liked-posts", controller.GetLikedPosts)
-		v1.GET("/users/:id/favorite-posts", controller.GetFavoritePosts)
-		v1.GET("/users/:id/posts", controller.GetUserPosts)
+		v1.GET("/users/:id/liked-posts", middleware.SoftJWTAuth(), controller.GetLikedPosts)
+		v1.GET("/users/:id/favorite-posts", middleware.SoftJWTAuth(), controller.GetFavoritePosts)
+		v1.GET("/users/:id/posts", middleware.SoftJWTAuth(), controller.GetUserPosts)
+		v1.GET("/users/:id/series", controller.GetUserSeries)
+		v1.GET("/users/:id/collections", middleware.SoftJWTAuth(), controller.GetUserCollections)
+		v1.GET("/collections/:id", middleware.SoftJWTAuth(), controller.GetCollection)
 		v1.GET("/users/:id", middleware.SoftJWTAuth(), controller.GetUserProfile)
 
-		// 需要认证的路由组
+		// 需要认证的路由组（仅接受登录令牌）
 		auth := v1.Group("/")
 		auth.Use(middleware.JWTAuth())
 		{
-			auth.POST("/posts", controller.CreatePost)
-			auth.PUT("/posts/:id", controller.UpdatePost)
-			auth.DELETE("/posts/:id", controller.DeletePost)
 			auth.POST("/posts/:id/like", controller.ToggleLike)
 			auth.POST("/posts/:id/favorite", controller.ToggleFavorite)
+			auth.POST("/posts/:id/repost", controller.ToggleRepost)
+			auth.POST("/posts/:id/quote", controller.QuotePost)
 			auth.POST("/posts/:id/top", controller.ToggleTop)
 			auth.POST("/posts/:id/system-top", controller.ToggleSystemTop)
+			auth.POST("/posts/:id/reactions", controller.TogglePostReaction)
+			auth.POST("/comments/:id/reactions", controller.ToggleCommentReaction)
 
-			// Comments
-			auth.POST("/comments", controller.CreateComment)
-			auth.DELETE("/comments/:id", controller.DeleteComment)
-			auth.GET("/my/comments", controller.GetMyComments)
-			auth.GET("/my/post-comments", controller.GetCommentsOnMyPosts)
+			// Collections
+			auth.GET("/my/collections", controller.GetMyCollections)
+			auth.POST("/collections", controller.CreateCollection)
+			auth.PUT("/collections/:id", controller.UpdateCollection)
+			auth.DELETE("/collections/:id", controller.DeleteCollection)
+			auth.POST("/collections/:id/items", controller.AddCollectionItem)
+			auth.PUT("/collections/:id/items/:post_id", controller.UpdateCollectionItem)
+			auth.DELETE("/collections/:id/items/:post_id", controller.RemoveCollectionItem)
+			auth.PUT("/collections/:id/order", controller.ReorderCollectionItems)
 
 			// Notifications
-			auth.GET("/notifications", controller.GetNotifications)
 			auth.GET("/notifications/unread-count", controller.GetUnreadNotificationCount)
 			auth.PUT("/notifications/:id/read", controller.MarkNotificationRead)
 
 			// User Relations
 			auth.POST("/users/:id/follow", controller.FollowUser)
 			auth.POST("/users/:id/unfollow", controller.UnfollowUser)
+			auth.GET("/my/follow-requests", controller.GetFollowRequests)
+			auth.POST("/my/follow-requests/:id/approve", controller.ApproveFollowRequest)
+			auth.POST("/my/follow-requests/:id/reject", controller.RejectFollowRequest)
+			auth.POST("/users/:id/block", controller.BlockUser)
+			auth.POST("/users/:id/unblock", controller.UnblockUser)
+			auth.POST("/users/:id/mute", controller.MuteUser)
+			auth.POST("/users/:id/unmute", controller.UnmuteUser)
+			auth.GET("/my/blocks", controller.GetBlockedUsers)
+			auth.GET("/my/mutes", controller.GetMutedUsers)
+			auth.POST("/tags/:name/follow", controller.FollowTag)
+			auth.POST("/tags/:name/unfollow", controller.UnfollowTag)
+			auth.GET("/my/followed-tags", controller.GetFollowedTags)
+
+			// Feed
+			auth.GET("/feed", controller.GetFeed)
+			auth.GET("/recommendations/users", controller.GetUserRecommendations)
+
+			// Direct Messages
+			auth.GET("/conversations", controller.GetConversations)
+			auth.GET("/conversations/:id", controller.GetConversation)
+			auth.GET("/conversations/:id/messages", controller.GetMessages)
+			auth.POST("/conversations/:id/messages", controller.ReplyMessage)
+			auth.POST("/conversations/:id/read", controller.MarkConversationRead)
+			auth.POST("/conversations/:id/mute", controller.MuteConversation)
+			auth.POST("/conversations/:id/unmute", controller.UnmuteConversation)
+			auth.POST("/users/:id/messages", controller.SendMessage)
+			auth.GET("/messages/unread-count", controller.GetUnreadMessageCount)
 
 			// User Profile Update
 			auth.PUT("/user/profile", controller.UpdateProfile)
 			auth.POST("/user/avatar", controller.UploadAvatar)
-			auth.POST("/upload/image", controller.UploadImage)
+			auth.PUT("/user/password", controller.ChangePassword)
+			auth.PUT("/user/username", controller.ChangeUsername)
+			auth.PUT("/user/privacy", controller.UpdatePrivacy)
+			auth.PUT("/user/mention-notify", controller.UpdateMentionNotify)
+			auth.PUT("/user/message-privacy", controller.UpdateMessagePrivacy)
+			auth.POST("/user/email/verification", controller.ResendEmailVerification)
+
+			// Two-Factor Authentication
+			auth.GET("/user/2fa", controller.GetTwoFactorStatus)
+			auth.POST("/user/2fa/enroll", controller.EnrollTwoFactor)
+			auth.POST("/user/2fa/confirm", controller.ConfirmTwoFactor)
+			auth.POST("/user/2fa/disable", controller.DisableTwoFactor)
+			auth.POST("/user/2fa/recovery-codes", controller.RegenerateRecoveryCodes)
+
+			// External Identities
+			auth.GET("/user/identities", controller.GetIdentities)
+			auth.POST("/user/identities/:provider", controller.LinkIdentity)
+			auth.DELETE("/user/identities/:id", controller.UnlinkIdentity)
+
+			// Personal Access Tokens
+			auth.GET("/user/tokens", controller.GetAccessTokens)
+			auth.POST("/user/tokens", controller.CreateAccessToken)
+			auth.DELETE("/user/tokens/:id", controller.RevokeAccessToken)
+
+			// Sessions
+			auth.GET("/my/sessions", controller.GetSessions)
+			auth.DELETE("/my/sessions", controller.RevokeOtherSessions)
+			auth.DELETE("/my/sessions/:id", controller.RevokeSession)
+			auth.POST("/logout", controller.Logout)
+
+			// Personal Data
+			auth.POST("/my/exports", controller.RequestDataExport)
+			auth.GET("/my/exports", controller.GetDataExports)
+			auth.GET("/my/exports/:id/download", controller.DownloadDataExport)
+			auth.POST("/my/account/deletion", controller.RequestAccountDeletion)
+			auth.DELETE("/my/account/deletion", controller.CancelAccountDeletion)
+
+			// Invites
+			auth.GET("/my/invites", controller.GetMyInvites)
+			auth.POST("/my/invites", controller.CreateMyInvite)
+			auth.DELETE("/my/invites/:id", controller.RevokeInvite)
+		}
+
+		// 同时接受个人访问令牌的路由组，令牌需具备对应权限范围
+		api := v1.Group("/")
+		api.Use(middleware.TokenAuth())
+		{
+			api.POST("/posts", middleware.RequireScope(service.ScopePostsWrite), controller.CreatePost)
+			api.PUT("/posts/:id", middleware.RequireScope(service.ScopePostsWrite), controller.UpdatePost)
+			api.DELETE("/posts/:id", middleware.RequireScope(service.ScopePostsWrite), controller.DeletePost)
+			api.POST("/upload/image", middleware.RequireScope(service.ScopeMediaWrite), controller.UploadImage)
+
+			// Series
+			api.POST("/series", middleware.RequireScope(service.ScopePostsWrite), controller.CreateSeries)
+			api.PUT("/series/:id", middleware.RequireScope(service.ScopePostsWrite), controller.UpdateSeries)
+			api.PUT("/series/:id/posts", middleware.RequireScope(service.ScopePostsWrite), controller.SetSeriesPosts)
+			api.DELETE("/series/:id", middleware.RequireScope(service.ScopePostsWrite), controller.DeleteSeries)
+
+			// Comments
+			api.POST("/comments", middleware.RequireScope(service.ScopeCommentsWrite), controller.CreateComment)
+			api.DELETE("/comments/:id", middleware.RequireScope(service.ScopeCommentsWrite), controller.DeleteComment)
+			api.GET("/my/comments", middleware.RequireScope(service.ScopeCommentsRead), controller.GetMyComments)
+			api.GET("/my/post-comments", middleware.RequireScope(service.ScopeCommentsRead), controller.GetCommentsOnMyPosts)
+
+			// Notifications
+			api.GET("/notifications", middleware.RequireScope(service.ScopeNotificationsRead), controller.GetNotifications)
+			api.GET("/my/mentions", middleware.RequireScope(service.ScopeNotificationsRead), controller.GetMentions)
+		}
+
+		// 管理员路由组
+		admin := v1.Group("/admin")
+		admin.Use(middleware.JWTAuth(), middleware.AdminOnly())
+		{
+			admin.PUT("/users/:id/role", controller.UpdateUserRole)
+			admin.POST("/login-locks/unlock", controller.UnlockLogin)
+			admin.GET("/audit-logs", controller.GetAuditLogs)
+			admin.GET("/audit-logs/export", controller.ExportAuditLogs)
+			admin.GET("/mails/failed", controller.GetFailedMails)
+			admin.POST("/mails/:id/retry", controller.RetryFailedMail)
+			admin.GET("/settings", controller.GetSettings)
+			admin.PUT("/settings/:key", controller.UpdateSetting)
+			admin.GET("/invites", controller.GetInvites)
+			admin.POST("/invites", controller.CreateInvite)
+			admin.DELETE("/invites/:id", controller.RevokeInvite)
+			admin.POST("/categories", controller.CreateCategory)
+			admin.PUT("/categories/:id", controller.UpdateCategory)
+			admin.DELETE("/categories/:id", controller.DeleteCategory)
+			admin.PUT("/tags/:id", controller.RenameTag)
+			admin.POST("/tags/:id/merge", controller.MergeTags)
+			admin.GET("/tags/:id/synonyms", controller.GetTagSynonyms)
+			admin.POST("/tags/:id/synonyms", controller.AddTagSynonym)
+			admin.DELETE("/tags/:id/synonyms/:synonym_id", controller.RemoveTagSynonym)
 		}
 	}
 
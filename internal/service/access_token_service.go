@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+	"time"
+)
+
+// 个人访问令牌的权限范围
+const (
+	ScopePostsWrite        = "posts:write"
+	ScopeCommentsRead      = "comments:read"
+	ScopeCommentsWrite     = "comments:write"
+	ScopeMediaWrite        = "media:write"
+	ScopeNotificationsRead = "notifications:read"
+)
+
+var validScopes = map[string]bool{
+	ScopePostsWrite:        true,
+	ScopeCommentsRead:      true,
+	ScopeCommentsWrite:     true,
+	ScopeMediaWrite:        true,
+	ScopeNotificationsRead: true,
+}
+
+const (
+	// AccessTokenPrefix 个人访问令牌的固定前缀，用于和 JWT 区分
+	AccessTokenPrefix = "sbp_"
+
+	maxAccessTokensPerUser = 50
+	// 最近使用时间的更新间隔，避免每个请求都写库
+	accessTokenTouchInterval = time.Minute
+)
+
+type AccessTokenService struct{}
+
+// CreateToken 创建个人访问令牌，明文令牌只在创建时返回一次
+func (s *AccessTokenService) CreateToken(userID uint, name string, scopes []string, expiresAt *time.Time) (string, *model.PersonalAccessToken, error) {
+	if len(scopes) == 0 {
+		return "", nil, errors.New("at least one scope is required")
+	}
+	seen := make(map[string]bool)
+	var normalized []string
+	for _, scope := range scopes {
+		if !validScopes[scope] {
+			return "", nil, errors.New("invalid scope: " + scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			normalized = append(normalized, scope)
+		}
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return "", nil, errors.New("expiry must be in the future")
+	}
+
+	var count int64
+	database.DB.Model(&model.PersonalAccessToken{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxAccessTokensPerUser {
+		return "", nil, errors.New("too many access tokens")
+	}
+
+	random, err := randomToken(20)
+	if err != nil {
+		return "", nil, err
+	}
+	raw := AccessTokenPrefix + random
+
+	token := model.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(raw),
+		Prefix:    raw[:len(AccessTokenPrefix)+6],
+		Scopes:    strings.Join(normalized, ","),
+		ScopeList: normalized,
+		ExpiresAt: expiresAt,
+	}
+	if err := database.DB.Create(&token).Error; err != nil {
+		return "", nil, err
+	}
+	return raw, &token, nil
+}
+
+func (s *AccessTokenService) ListTokens(userID uint) ([]model.PersonalAccessToken, error) {
+	var tokens []model.PersonalAccessToken
+	err := database.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&tokens).Error
+	return tokens, err
+}
+
+// RevokeToken 吊销令牌，立即失效
+func (s *AccessTokenService) RevokeToken(userID, tokenID uint) error {
+	result := database.DB.Where("id = ? AND user_id = ?", tokenID, userID).Delete(&model.PersonalAccessToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("token not found")
+	}
+	return nil
+}
+
+// Authenticate 校验个人访问令牌并记录最近使用时间
+func (s *AccessTokenService) Authenticate(raw, ip string) (*model.PersonalAccessToken, error) {
+	var token model.PersonalAccessToken
+	if err := database.DB.Where("token_hash = ?", hashToken(raw)).First(&token).Error; err != nil {
+		return nil, errors.New("invalid access token")
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && now.After(*token.ExpiresAt) {
+		return nil, errors.New("access token expired")
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > accessTokenTouchInterval {
+		database.DB.Model(&model.PersonalAccessToken{}).Where("id = ?", token.ID).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		})
+	}
+	return &token, nil
+}
+
+// IsAccessToken 判断 Bearer 令牌是否为个人访问令牌
+func IsAccessToken(raw string) bool {
+	return strings.HasPrefix(raw, AccessTokenPrefix)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	DeletionModeAnonymize = "anonymize" // 保留文章和评论，作者显示为匿名账号
+	DeletionModeDelete    = "delete"    // 同时删除文章和评论
+
+	accountDeletionGracePeriod   = 14 * 24 * time.Hour
+	accountDeletionCheckInterval = 10 * time.Minute
+)
+
+// AccountDeletionService 账号注销：申请后进入冷静期，到期由后台任务执行
+type AccountDeletionService struct{}
+
+var accountDeletionService = new(AccountDeletionService)
+
+// StartAccountDeletionWorker 启动后台任务，执行冷静期已满的注销申请
+func StartAccountDeletionWorker() {
+	go func() {
+		ticker := time.NewTicker(accountDeletionCheckInterval)
+		defer ticker.Stop()
+		for {
+			accountDeletionService.ProcessDue()
+			<-ticker.C
+		}
+	}()
+}
+
+// RequestDeletion 申请注销账号，需要验证密码，返回计划执行时间
+func (s *AccountDeletionService) RequestDeletion(userID uint, password, mode string) (*time.Time, error) {
+	if mode != DeletionModeAnonymize && mode != DeletionModeDelete {
+		return nil, errors.New("invalid deletion mode")
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.DeletionScheduledAt != nil {
+		return nil, errors.New("account deletion already scheduled")
+	}
+	if user.Role == "admin" {
+		return nil, errors.New("administrators must be demoted before deleting their account")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, errors.New("password is incorrect")
+	}
+
+	scheduledAt := time.Now().Add(accountDeletionGracePeriod)
+	err := database.DB.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"deletion_scheduled_at": scheduledAt,
+		"deletion_mode":         mode,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Email != "" {
+		err := mailService.Enqueue(user.Email, "account_deletion_scheduled", user.Locale, map[string]interface{}{
+			"Username":    user.Username,
+			"ScheduledAt": scheduledAt.Format("2006-01-02 15:04:05"),
+			"Link":        config.GetAppConfig().BaseURL + "/settings",
+		})
+		if err != nil {
+			fmt.Printf("AccountDeletionService: failed to enqueue mail: %v\n", err)
+		}
+	}
+	return &scheduledAt, nil
+}
+
+// CancelDeletion 在冷静期内撤销注销申请
+func (s *AccountDeletionService) CancelDeletion(userID uint) error {
+	result := database.DB.Model(&model.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+		Updates(map[string]interface{}{
+			"deletion_scheduled_at": nil,
+			"deletion_mode":         "",
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("no deletion scheduled")
+	}
+	return nil
+}
+
+// ProcessDue 执行冷静期已满的注销申请
+func (s *AccountDeletionService) ProcessDue() {
+	var users []model.User
+	err := database.DB.Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).
+		Limit(20).
+		Find(&users).Error
+	if err != nil {
+		fmt.Printf("AccountDeletionService: failed to load users: %v\n", err)
+		return
+	}
+	for i := range users {
+		if err := s.purge(&users[i]); err != nil {
+			fmt.Printf("AccountDeletionService: failed to delete user %d: %v\n", users[i].ID, err)
+		}
+	}
+}
+
+// purge 清除账号的个人数据。用户记录本身只做匿名化，保证匿名保留的内容仍有作者可关联
+func (s *AccountDeletionService) purge(user *model.User) error {
+	var exports []model.DataExport
+	database.DB.Where("user_id = ?", user.ID).Find(&exports)
+
+	random, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	removeContent := user.DeletionMode == DeletionModeDelete
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if removeContent {
+			if err := s.deleteContent(tx, user.ID); err != nil {
+				return err
+			}
+		}
+
+		uid := user.ID
+		cleanups := []*gorm.DB{
+			tx.Exec("DELETE FROM user_likes WHERE user_id = ?", uid),
+			tx.Exec("DELETE FROM user_favorites WHERE user_id = ?", uid),
+			tx.Exec("DELETE FROM user_followers WHERE follower_id = ? OR followed_id = ?", uid, uid),
+			tx.Exec("DELETE FROM user_tag_follows WHERE user_id = ?", uid),
+			tx.Where("user_id = ? OR blocked_id = ?", uid, uid).Delete(&model.UserBlock{}),
+			tx.Where("follower_id = ? OR followed_id = ?", uid, uid).Delete(&model.FollowRequest{}),
+			tx.Where("user_id = ?", uid).Delete(&model.Reaction{}),
+			tx.Where("user_id = ? OR author_id = ?", uid, uid).Delete(&model.Mention{}),
+			// 私信会话保留给另一方，只移除该用户的成员记录；双方都已注销的会话整个删除
+			tx.Where("user_id = ?", uid).Delete(&model.ConversationMember{}),
+			tx.Where("conversation_id IN (SELECT id FROM conversations WHERE (user_a_id = ? OR user_b_id = ?) "+
+				"AND id NOT IN (SELECT conversation_id FROM conversation_members))", uid, uid).Delete(&model.Message{}),
+			tx.Where("(user_a_id = ? OR user_b_id = ?) AND id NOT IN (SELECT conversation_id FROM conversation_members)", uid, uid).
+				Delete(&model.Conversation{}),
+			tx.Where("user_id = ? OR candidate_id = ?", uid, uid).Delete(&model.UserRecommendation{}),
+			tx.Where("collection_id IN (SELECT id FROM collections WHERE user_id = ?)", uid).Delete(&model.CollectionItem{}),
+			tx.Unscoped().Where("user_id = ?", uid).Delete(&model.Collection{}),
+			tx.Unscoped().Where("user_id = ? OR from_user_id = ?", uid, uid).Delete(&model.Notification{}),
+			tx.Unscoped().Where("user_id = ?", uid).Delete(&model.UserToken{}),
+			tx.Unscoped().Where("user_id = ?", uid).Delete(&model.RecoveryCode{}),
+			tx.Unscoped().Where("user_id = ?", uid).Delete(&model.PersonalAccessToken{}),
+			tx.Unscoped().Where("user_id = ?", uid).Delete(&model.UserIdentity{}),
+			tx.Unscoped().Where("user_id = ?", uid).Delete(&model.UserSession{}),
+			tx.Unscoped().Where("user_id = ?", uid).Delete(&model.DataExport{}),
+		}
+		for _, result := range cleanups {
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+
+		err := tx.Model(&model.User{}).Where("id = ?", uid).Updates(map[string]interface{}{
+			"username":              fmt.Sprintf("deleted_%d", uid),
+			"password":              string(hashedPassword),
+			"email":                 nil,
+			"email_verified":        false,
+			"avatar":                "",
+			"blog_name":             "",
+			"bio":                   "",
+			"role":                  "user",
+			"two_fa_enabled":        false,
+			"two_fa_secret":         "",
+			"deletion_scheduled_at": nil,
+			"message_from":          MessageFromFollowing, // 关注关系已清空，不再接收新私信
+		}).Error
+		if err != nil {
+			return err
+		}
+		if removeContent {
+			return tx.Delete(&model.User{}, uid).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 数据库提交后再删除文件；匿名保留的文章仍会引用文章图片
+	for _, export := range exports {
+		os.Remove(export.FilePath)
+	}
+	mediaDirs := []string{"avatars"}
+	if removeContent {
+		mediaDirs = append(mediaDirs, "posts")
+	}
+	uploadDir := config.GetAppConfig().UploadDir
+	for _, sub := range mediaDirs {
+		files, _ := filepath.Glob(filepath.Join(uploadDir, sub, fmt.Sprintf("%d_*", user.ID)))
+		for _, file := range files {
+			os.Remove(file)
+		}
+	}
+	return nil
+}
+
+// deleteContent 删除用户的文章（连同文章下的评论）、系列、评论和发出的私信
+func (s *AccountDeletionService) deleteContent(tx *gorm.DB, userID uint) error {
+	var postIDs []uint
+	if err := tx.Unscoped().Model(&model.Post{}).Where("user_id = ?", userID).Pluck("id", &postIDs).Error; err != nil {
+		return err
+	}
+	if len(postIDs) > 0 {
+		results := []*gorm.DB{
+			tx.Exec("DELETE FROM post_tags WHERE post_id IN ?", postIDs),
+			tx.Exec("DELETE FROM user_likes WHERE post_id IN ?", postIDs),
+			tx.Exec("DELETE FROM user_favorites WHERE post_id IN ?", postIDs),
+			tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&model.Notification{}),
+			tx.Where("target_type = ? AND target_id IN ?", ReactionTargetPost, postIDs).Delete(&model.Reaction{}),
+			tx.Where("target_type = ? AND target_id IN (SELECT id FROM comments WHERE post_id IN ?)", ReactionTargetComment, postIDs).Delete(&model.Reaction{}),
+			tx.Where("post_id IN ?", postIDs).Delete(&model.Mention{}),
+			tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&model.Comment{}),
+			tx.Unscoped().Where("post_id IN ?", postIDs).Delete(&model.PostSlugRedirect{}),
+			tx.Where("post_id IN ?", postIDs).Delete(&model.SeriesPost{}),
+			tx.Where("post_id IN ?", postIDs).Delete(&model.CollectionItem{}),
+			tx.Where("post_id IN ? OR related_id IN ?", postIDs, postIDs).Delete(&model.RelatedPost{}),
+			tx.Unscoped().Where("id IN ?", postIDs).Delete(&model.Post{}),
+		}
+		for _, result := range results {
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+	}
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&model.Series{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("sender_id = ?", userID).Delete(&model.Message{}).Error; err != nil {
+		return err
+	}
+	// 最近一条私信可能已被删除，改为剩余的最后一条
+	if err := tx.Exec("UPDATE conversations SET last_message_id = COALESCE((SELECT MAX(id) FROM messages "+
+		"WHERE messages.conversation_id = conversations.id), 0) WHERE user_a_id = ? OR user_b_id = ?", userID, userID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("target_type = ? AND target_id IN (SELECT id FROM comments WHERE user_id = ?)", ReactionTargetComment, userID).
+		Delete(&model.Reaction{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("user_id = ?", userID).Delete(&model.Comment{}).Error
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	TokenPurposePasswordReset = "password_reset"
+	TokenPurposeEmailVerify   = "email_verify"
+
+	passwordResetTTL = 30 * time.Minute
+	emailVerifyTTL   = 24 * time.Hour
+)
+
+// AccountService 负责密码修改、找回以及邮箱验证
+type AccountService struct{}
+
+var accountService = new(AccountService)
+
+// ChangePassword 已登录用户修改密码，需要校验旧密码。成功后注销当前会话以外的所有会话
+func (s *AccountService) ChangePassword(userID uint, currentSID, oldPassword, newPassword string) error {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(oldPassword)); err != nil {
+		return errors.New("old password is incorrect")
+	}
+
+	if err := s.setPassword(database.DB, user.ID, newPassword); err != nil {
+		return err
+	}
+	return sessionService.RevokeOtherSessions(user.ID, currentSID)
+}
+
+// RequestPasswordReset 发送密码重置邮件。邮箱不存在时也返回成功，避免泄露账号信息
+func (s *AccountService) RequestPasswordReset(email string) error {
+	var user model.User
+	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil
+	}
+
+	// 作废之前未使用的重置令牌
+	database.DB.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, TokenPurposePasswordReset).
+		Update("used_at", time.Now())
+
+	token, err := s.issueToken(user.ID, TokenPurposePasswordReset, user.Email, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return mailService.Enqueue(user.Email, "password_reset", user.Locale, map[string]interface{}{
+		"Username":      user.Username,
+		"Link":          fmt.Sprintf("%s/reset-password?token=%s", config.GetAppConfig().BaseURL, token),
+		"ExpireMinutes": int(passwordResetTTL.Minutes()),
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码，令牌只能使用一次。成功后注销该用户的所有会话
+func (s *AccountService) ResetPassword(token, newPassword string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		userToken, err := s.consumeToken(tx, token, TokenPurposePasswordReset)
+		if err != nil {
+			return err
+		}
+		if err := s.setPassword(tx, userToken.UserID, newPassword); err != nil {
+			return err
+		}
+		return sessionService.RevokeAllSessions(tx, userToken.UserID)
+	})
+}
+
+// SendEmailVerification 向用户当前邮箱重新发送验证邮件
+func (s *AccountService) SendEmailVerification(userID uint) error {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if user.EmailVerified {
+		return errors.New("email already verified")
+	}
+	return s.requestEmailVerification(&user, user.Email)
+}
+
+// VerifyEmail 使用验证令牌确认邮箱；如果是修改邮箱，则在此时替换为新邮箱
+func (s *AccountService) VerifyEmail(token string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		userToken, err := s.consumeToken(tx, token, TokenPurposeEmailVerify)
+		if err != nil {
+			return err
+		}
+
+		var count int64
+		tx.Model(&model.User{}).Where("email = ? AND id <> ?", userToken.Email, userToken.UserID).Count(&count)
+		if count > 0 {
+			return errors.New("email already in use")
+		}
+
+		return tx.Model(&model.User{}).Where("id = ?", userToken.UserID).Updates(map[string]interface{}{
+			"email":          userToken.Email,
+			"email_verified": true,
+		}).Error
+	})
+}
+
+// requestEmailVerification 为指定邮箱生成验证令牌并发送验证邮件
+func (s *AccountService) requestEmailVerification(user *model.User, email string) error {
+	// 作废之前未使用的验证令牌
+	database.DB.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.ID, TokenPurposeEmailVerify).
+		Update("used_at", time.Now())
+
+	token, err := s.issueToken(user.ID, TokenPurposeEmailVerify, email, emailVerifyTTL)
+	if err != nil {
+		return err
+	}
+
+	return mailService.Enqueue(email, "email_verify", user.Locale, map[string]interface{}{
+		"Username":    user.Username,
+		"Link":        fmt.Sprintf("%s/verify-email?token=%s", config.GetAppConfig().BaseURL, token),
+		"ExpireHours": int(emailVerifyTTL.Hours()),
+	})
+}
+
+func (s *AccountService) setPassword(tx *gorm.DB, userID uint, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return tx.Model(&model.User{}).Where("id = ?", userID).Update("password", string(hashedPassword)).Error
+}
+
+// issueToken 生成随机令牌，数据库中只保存其哈希
+func (s *AccountService) issueToken(userID uint, purpose, email string, ttl time.Duration) (string, error) {
+	raw, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	userToken := model.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: hashToken(raw),
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := database.DB.Create(&userToken).Error; err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// consumeToken 校验并标记令牌已使用，条件更新保证并发下只会成功一次
+func (s *AccountService) consumeToken(tx *gorm.DB, raw, purpose string) (*model.UserToken, error) {
+	var userToken model.UserToken
+	err := tx.Where("token_hash = ? AND purpose = ?", hashToken(raw), purpose).First(&userToken).Error
+	if err != nil || userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	result := tx.Model(&model.UserToken{}).
+		Where("id = ? AND used_at IS NULL", userToken.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("invalid or expired token")
+	}
+	return &userToken, nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"mime/quotedprintable"
+	"os"
+	"path/filepath"
+	"regexp"
+	"simple-blog/internal/database"
+	"simple-blog/internal/mailer"
+	"simple-blog/internal/model"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var initTestDB sync.Once
+
+// setupTestDB 连接 TEST_DB_DSN 指定的 MySQL 测试库并执行迁移，未配置时跳过测试
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+	initTestDB.Do(func() {
+		os.Setenv("DB_DSN", dsn)
+		database.InitDB()
+	})
+}
+
+// createTestUser 创建一个用户名和邮箱唯一的测试用户
+func createTestUser(t *testing.T, password string) *model.User {
+	t.Helper()
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	user := model.User{
+		Username:      "test_" + suffix,
+		Password:      string(hashed),
+		Email:         "test_" + suffix + "@example.com",
+		EmailVerified: true,
+	}
+	if err := database.DB.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &user
+}
+
+// useFileMailer 将邮件队列的投递改为写入临时目录，返回该目录
+func useFileMailer(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	previous := mailService.Mailer
+	mailService.Mailer = &mailer.FileMailer{Dir: dir, From: "no-reply@localhost"}
+	t.Cleanup(func() { mailService.Mailer = previous })
+	return dir
+}
+
+var tokenPattern = regexp.MustCompile(`token=([0-9a-f]{64})`)
+
+// tokenFromMail 投递队列中的邮件，从发给 to 的邮件中取出链接里的令牌
+func tokenFromMail(t *testing.T, dir, to string) string {
+	t.Helper()
+	mailService.ProcessQueue()
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(raw), "To: "+to) {
+			continue
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(string(raw))))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m := tokenPattern.FindStringSubmatch(string(body)); m != nil {
+			return m[1]
+		}
+	}
+	t.Fatalf("no mail with token sent to %s", to)
+	return ""
+}
+
+func TestPasswordResetFlow(t *testing.T) {
+	setupTestDB(t)
+	dir := useFileMailer(t)
+	user := createTestUser(t, "old-password")
+
+	if err := accountService.RequestPasswordReset(user.Email); err != nil {
+		t.Fatalf("request reset: %v", err)
+	}
+	token := tokenFromMail(t, dir, user.Email)
+
+	if err := accountService.ResetPassword(token, "new-password"); err != nil {
+		t.Fatalf("reset password: %v", err)
+	}
+	var updated model.User
+	database.DB.First(&updated, user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(updated.Password), []byte("new-password")) != nil {
+		t.Fatal("password was not changed")
+	}
+
+	if err := accountService.ResetPassword(token, "another-password"); err == nil || err.Error() != "invalid or expired token" {
+		t.Fatalf("reused token: got %v, want invalid or expired token", err)
+	}
+	database.DB.First(&updated, user.ID)
+	if bcrypt.CompareHashAndPassword([]byte(updated.Password), []byte("new-password")) != nil {
+		t.Fatal("reused token changed the password")
+	}
+}
+
+func TestEmailVerificationFlow(t *testing.T) {
+	setupTestDB(t)
+	dir := useFileMailer(t)
+	user := createTestUser(t, "password")
+	database.DB.Model(user).Update("email_verified", false)
+
+	if err := accountService.SendEmailVerification(user.ID); err != nil {
+		t.Fatalf("send verification: %v", err)
+	}
+	token := tokenFromMail(t, dir, user.Email)
+
+	if err := accountService.VerifyEmail(token); err != nil {
+		t.Fatalf("verify email: %v", err)
+	}
+	var updated model.User
+	database.DB.First(&updated, user.ID)
+	if !updated.EmailVerified {
+		t.Fatal("email was not marked verified")
+	}
+	if err := accountService.VerifyEmail(token); err == nil {
+		t.Fatal("expected reused verification token to be rejected")
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Actor 描述发起操作的用户及请求来源，用于权限判断和审计
+type Actor struct {
+	UserID    uint
+	Role      string
+	IP        string
+	UserAgent string
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == "admin"
+}
+
+// AuditFilter 审计日志查询条件
+type AuditFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   uint
+	From       *time.Time
+	To         *time.Time
+}
+
+// 单次导出的最大条数
+const auditExportLimit = 10000
+
+type AuditService struct{}
+
+// Record 写入一条审计日志，before/after 会被序列化为 JSON 快照
+func (s *AuditService) Record(actor Actor, action, targetType string, targetID uint, before, after interface{}) error {
+	return s.record(database.DB, actor, action, targetType, targetID, before, after)
+}
+
+// record 允许在事务中写入审计日志，保证与业务操作同时成功或失败
+func (s *AuditService) record(tx *gorm.DB, actor Actor, action, targetType string, targetID uint, before, after interface{}) error {
+	entry := model.AuditLog{
+		ActorID:    actor.UserID,
+		ActorRole:  actor.Role,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     auditSnapshot(before),
+		After:      auditSnapshot(after),
+		IP:         actor.IP,
+		UserAgent:  truncate(actor.UserAgent, 255),
+	}
+	return tx.Create(&entry).Error
+}
+
+func (s *AuditService) ListLogs(filter AuditFilter, page, pageSize int) ([]model.AuditLog, int64, error) {
+	var logs []model.AuditLog
+	var total int64
+
+	db := s.applyFilter(database.DB.Model(&model.AuditLog{}), filter)
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := db.Preload("Actor").
+		Order("created_at desc, id desc").
+		Offset(offset).Limit(pageSize).
+		Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range logs {
+		logs[i].Actor.Password = ""
+	}
+	return logs, total, nil
+}
+
+// ExportLogs 按条件导出审计日志，format 支持 csv 和 json
+func (s *AuditService) ExportLogs(filter AuditFilter, format string, w io.Writer) error {
+	var logs []model.AuditLog
+	err := s.applyFilter(database.DB.Model(&model.AuditLog{}), filter).
+		Order("created_at desc, id desc").
+		Limit(auditExportLimit).
+		Find(&logs).Error
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		return json.NewEncoder(w).Encode(logs)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "created_at", "actor_id", "actor_role", "action", "target_type", "target_id", "before", "after", "ip", "user_agent"})
+		for _, l := range logs {
+			cw.Write([]string{
+				strconv.FormatUint(uint64(l.ID), 10),
+				l.CreatedAt.Format(time.RFC3339),
+				strconv.FormatUint(uint64(l.ActorID), 10),
+				l.ActorRole,
+				l.Action,
+				l.TargetType,
+				strconv.FormatUint(uint64(l.TargetID), 10),
+				l.Before,
+				l.After,
+				l.IP,
+				l.UserAgent,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		return errors.New("unsupported export format")
+	}
+}
+
+func (s *AuditService) applyFilter(db *gorm.DB, filter AuditFilter) *gorm.DB {
+	if filter.ActorID > 0 {
+		db = db.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		db = db.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		db = db.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID > 0 {
+		db = db.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		db = db.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		db = db.Where("created_at <= ?", *filter.To)
+	}
+	return db
+}
+
+func auditSnapshot(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
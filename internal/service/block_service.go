@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	BlockKindBlock = "block"
+	BlockKindMute  = "mute"
+)
+
+// BlockService 用户的屏蔽和静音列表
+type BlockService struct{}
+
+var blockService = new(BlockService)
+
+// Block 屏蔽用户，同时解除双方之间的关注关系和关注请求
+func (s *BlockService) Block(userID, targetID uint) error {
+	if err := s.checkTarget(userID, targetID); err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.add(tx, userID, targetID, BlockKindBlock); err != nil {
+			return err
+		}
+		if err := tx.Where("(follower_id = ? AND followed_id = ?) OR (follower_id = ? AND followed_id = ?)",
+			userID, targetID, targetID, userID).Delete(&model.FollowRequest{}).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM user_followers WHERE (follower_id = ? AND followed_id = ?) OR (follower_id = ? AND followed_id = ?)",
+			userID, targetID, targetID, userID).Error
+	})
+}
+
+// Unblock 取消屏蔽
+func (s *BlockService) Unblock(userID, targetID uint) error {
+	return s.remove(userID, targetID, BlockKindBlock)
+}
+
+// Mute 静音用户，对方的文章不再出现在时间线中，其他互动不受影响
+func (s *BlockService) Mute(userID, targetID uint) error {
+	if err := s.checkTarget(userID, targetID); err != nil {
+		return err
+	}
+	return s.add(database.DB, userID, targetID, BlockKindMute)
+}
+
+// Unmute 取消静音
+func (s *BlockService) Unmute(userID, targetID uint) error {
+	return s.remove(userID, targetID, BlockKindMute)
+}
+
+// List 列出用户屏蔽或静音的用户
+func (s *BlockService) List(userID uint, kind string) ([]model.UserBlock, error) {
+	var list []model.UserBlock
+	err := database.DB.Preload("Blocked").
+		Where("user_id = ? AND kind = ?", userID, kind).
+		Order("created_at desc").
+		Find(&list).Error
+	return list, err
+}
+
+// HasBlocked 判断 userID 是否屏蔽了 targetID
+func (s *BlockService) HasBlocked(userID, targetID uint) bool {
+	if userID == 0 || targetID == 0 {
+		return false
+	}
+	var count int64
+	database.DB.Model(&model.UserBlock{}).
+		Where("user_id = ? AND blocked_id = ? AND kind = ?", userID, targetID, BlockKindBlock).
+		Count(&count)
+	return count > 0
+}
+
+// HasMuted 判断 userID 是否静音了 targetID
+func (s *BlockService) HasMuted(userID, targetID uint) bool {
+	if userID == 0 || targetID == 0 {
+		return false
+	}
+	var count int64
+	database.DB.Model(&model.UserBlock{}).
+		Where("user_id = ? AND blocked_id = ? AND kind = ?", userID, targetID, BlockKindMute).
+		Count(&count)
+	return count > 0
+}
+
+// excludeHiddenUsers 在查询中排除 viewer 屏蔽或静音（由 kinds 指定）的用户，column 为作者字段，未登录时不过滤
+func excludeHiddenUsers(db *gorm.DB, viewerID uint, column string, kinds ...string) *gorm.DB {
+	if viewerID == 0 {
+		return db
+	}
+	return db.Where(column+" NOT IN (SELECT blocked_id FROM user_blocks WHERE user_id = ? AND kind IN ?)", viewerID, kinds)
+}
+
+func (s *BlockService) checkTarget(userID, targetID uint) error {
+	if userID == targetID {
+		return errors.New("cannot block yourself")
+	}
+	var count int64
+	database.DB.Model(&model.User{}).Where("id = ?", targetID).Count(&count)
+	if count == 0 {
+		return errors.New("user not found")
+	}
+	return nil
+}
+
+func (s *BlockService) add(tx *gorm.DB, userID, targetID uint, kind string) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.UserBlock{UserID: userID, BlockedID: targetID, Kind: kind}).Error
+}
+
+func (s *BlockService) remove(userID, targetID uint, kind string) error {
+	return database.DB.Where("user_id = ? AND blocked_id = ? AND kind = ?", userID, targetID, kind).
+		Delete(&model.UserBlock{}).Error
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"simple-blog/internal/captcha"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	captchaTTL        = 5 * time.Minute
+	captchaRateLimit  = 20          // 同一 IP 在 captchaRateWindow 内最多获取的验证码数量
+	captchaRateWindow = time.Minute // 超过该时间没有请求则重新计数
+)
+
+// CaptchaRateLimitedError 同一 IP 获取验证码过于频繁
+type CaptchaRateLimitedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *CaptchaRateLimitedError) Error() string {
+	return "too many captcha requests, please try again later"
+}
+
+// CaptchaAnswer 客户端提交的验证码
+type CaptchaAnswer struct {
+	ID     string
+	Answer string
+}
+
+// CaptchaService 自托管的图形验证码。获取验证码按 IP 限流，计数使用与登录失败计数相同的存储方式
+type CaptchaService struct {
+	Store LoginAttemptStore
+
+	mu          sync.Mutex
+	lastCleanup time.Time
+}
+
+var captchaService = NewCaptchaService()
+
+func NewCaptchaService() *CaptchaService {
+	// 内存存储会按调用方的计数窗口清理条目，不能与登录失败计数共用同一个实例
+	var store LoginAttemptStore = &DBAttemptStore{}
+	if config.GetAppConfig().LoginAttemptStore == "memory" {
+		store = NewMemoryAttemptStore()
+	}
+	return &CaptchaService{Store: store}
+}
+
+// New 为 ip 生成验证码，返回验证码ID和 data URI 格式的图片
+func (s *CaptchaService) New(ip string) (string, string, error) {
+	now := time.Now()
+	state, err := s.Store.Increment(captchaAttemptKey(ip), now, captchaRateWindow)
+	if err != nil {
+		fmt.Printf("CaptchaService: failed to record %s: %v\n", ip, err)
+	} else if state.Failures > captchaRateLimit {
+		return "", "", &CaptchaRateLimitedError{RetryAfter: captchaRateWindow}
+	}
+
+	challenge := captcha.NewChallenge()
+	img, err := captcha.Render(challenge.Question)
+	if err != nil {
+		return "", "", err
+	}
+	id, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.cleanupExpired(now)
+
+	record := model.Captcha{
+		TokenHash: hashToken(id),
+		Answer:    challenge.Answer,
+		ExpiresAt: time.Now().Add(captchaTTL),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		return "", "", err
+	}
+	return id, captcha.DataURI(img), nil
+}
+
+// cleanupExpired 清理过期的验证码，每个有效期内最多执行一次
+func (s *CaptchaService) cleanupExpired(now time.Time) {
+	s.mu.Lock()
+	if now.Sub(s.lastCleanup) < captchaTTL {
+		s.mu.Unlock()
+		return
+	}
+	s.lastCleanup = now
+	s.mu.Unlock()
+	database.DB.Unscoped().Where("expires_at < ?", now).Delete(&model.Captcha{})
+}
+
+func captchaAttemptKey(ip string) string {
+	return "captcha:" + ip
+}
+
+// Verify 校验验证码。无论是否答对，验证码都只能使用一次
+func (s *CaptchaService) Verify(answer CaptchaAnswer) error {
+	if answer.ID == "" || answer.Answer == "" {
+		return errors.New("captcha required")
+	}
+
+	var record model.Captcha
+	if err := database.DB.Where("token_hash = ?", hashToken(answer.ID)).First(&record).Error; err != nil {
+		return errors.New("invalid captcha")
+	}
+	result := database.DB.Unscoped().Delete(&model.Captcha{}, record.ID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return errors.New("invalid captcha")
+	}
+	if time.Now().After(record.ExpiresAt) || strings.TrimSpace(answer.Answer) != record.Answer {
+		return errors.New("invalid captcha")
+	}
+	return nil
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"simple-blog/internal/slug"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const maxCategoryDepth = 5
+
+// CategoryInput 创建或修改分类时的参数
+type CategoryInput struct {
+	ParentID    *uint
+	Name        string
+	Slug        string
+	Description string
+	SortOrder   int
+}
+
+// CategoryService 分类树的维护与查询，分类数量有限，整棵树在内存中处理
+type CategoryService struct{}
+
+var categoryService = new(CategoryService)
+
+// Tree 返回完整的分类树，每个分类的文章数包含其所有子分类
+func (s *CategoryService) Tree() ([]*model.Category, error) {
+	all, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+	err = database.DB.Model(&model.Post{}).
+		Select("category_id, COUNT(*) as count").
+		Where("category_id IS NOT NULL AND status = ?", "published").
+		Group("category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*model.Category, len(all))
+	for i := range all {
+		byID[all[i].ID] = &all[i]
+	}
+	for _, row := range rows {
+		if c, ok := byID[row.CategoryID]; ok {
+			c.PostCount = row.Count
+		}
+	}
+
+	var roots []*model.Category
+	for i := range all {
+		c := &all[i]
+		if parent, ok := byID[parentOf(c)]; ok {
+			parent.Children = append(parent.Children, c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+	for _, root := range roots {
+		sumPostCounts(root)
+	}
+	return roots, nil
+}
+
+// Resolve 通过 slug 或数字ID查找分类
+func (s *CategoryService) Resolve(key string) (*model.Category, error) {
+	var category model.Category
+	db := database.DB.Where("slug = ?", key)
+	if id, err := strconv.Atoi(key); err == nil {
+		db = database.DB.Where("id = ?", id)
+	}
+	if err := db.First(&category).Error; err != nil {
+		return nil, errors.New("category not found")
+	}
+	return &category, nil
+}
+
+// DescendantIDs 返回分类自身及其所有子孙分类的ID
+func (s *CategoryService) DescendantIDs(id uint) ([]uint, error) {
+	all, err := s.all()
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[uint][]uint)
+	for _, c := range all {
+		children[parentOf(&c)] = append(children[parentOf(&c)], c.ID)
+	}
+	ids := []uint{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+// CreateCategory 创建分类（仅管理员），未指定 slug 时根据名称生成
+func (s *CategoryService) CreateCategory(actor Actor, input CategoryInput) (*model.Category, error) {
+	if !actor.IsAdmin() {
+		return nil, errors.New("unauthorized")
+	}
+
+	category := model.Category{}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.apply(tx, &category, input); err != nil {
+			return err
+		}
+		if err := tx.Create(&category).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "category.create", "category", category.ID, nil, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// UpdateCategory 修改分类（仅管理员），可以移动到其他父分类下
+func (s *CategoryService) UpdateCategory(id uint, actor Actor, input CategoryInput) (*model.Category, error) {
+	if !actor.IsAdmin() {
+		return nil, errors.New("unauthorized")
+	}
+
+	var category model.Category
+	if err := database.DB.First(&category, id).Error; err != nil {
+		return nil, errors.New("category not found")
+	}
+	before := category
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.apply(tx, &category, input); err != nil {
+			return err
+		}
+		// Select 全部字段，确保 parent_id 置空和 sort_order 归零也能保存
+		if err := tx.Model(&category).Select("parent_id", "name", "slug", "description", "sort_order").Updates(&category).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "category.update", "category", category.ID, before, category)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// DeleteCategory 删除分类（仅管理员）。有子分类时不能删除，其下文章变为未分类
+func (s *CategoryService) DeleteCategory(id uint, actor Actor) error {
+	if !actor.IsAdmin() {
+		return errors.New("unauthorized")
+	}
+
+	var category model.Category
+	if err := database.DB.First(&category, id).Error; err != nil {
+		return errors.New("category not found")
+	}
+
+	var children int64
+	database.DB.Model(&model.Category{}).Where("parent_id = ?", id).Count(&children)
+	if children > 0 {
+		return errors.New("category has subcategories")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Post{}).Where("category_id = ?", id).Update("category_id", nil).Error; err != nil {
+			return err
+		}
+		// 硬删除，释放 slug
+		if err := tx.Unscoped().Delete(&category).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "category.delete", "category", category.ID, category, nil)
+	})
+}
+
+// apply 校验输入并写入分类，父分类不能是自身或自身的子孙，树深度有上限
+func (s *CategoryService) apply(tx *gorm.DB, category *model.Category, input CategoryInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+
+	key := strings.TrimSpace(input.Slug)
+	if key == "" {
+		key = slug.Make(name)
+	}
+	if !slug.Valid(key) {
+		return errors.New("invalid slug")
+	}
+	var count int64
+	tx.Model(&model.Category{}).Where("slug = ? AND id <> ?", key, category.ID).Count(&count)
+	if count > 0 {
+		return errors.New("slug already in use")
+	}
+
+	if input.ParentID != nil && *input.ParentID != 0 {
+		all, err := s.all()
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint]*model.Category, len(all))
+		for i := range all {
+			byID[all[i].ID] = &all[i]
+		}
+		parent, ok := byID[*input.ParentID]
+		if !ok {
+			return errors.New("parent category not found")
+		}
+
+		depth := 1
+		for p := parent; p != nil; p = byID[parentOf(p)] {
+			if category.ID != 0 && p.ID == category.ID {
+				return errors.New("invalid parent category")
+			}
+			depth++
+		}
+		if depth+s.subtreeHeight(all, category.ID) > maxCategoryDepth {
+			return errors.New("category tree too deep")
+		}
+		category.ParentID = &parent.ID
+	} else {
+		category.ParentID = nil
+	}
+
+	category.Name = name
+	category.Slug = key
+	category.Description = input.Description
+	category.SortOrder = input.SortOrder
+	return nil
+}
+
+// subtreeHeight 返回分类下方子孙的层数，新建分类为 0
+func (s *CategoryService) subtreeHeight(all []model.Category, id uint) int {
+	if id == 0 {
+		return 0
+	}
+	height := 0
+	for _, c := range all {
+		if parentOf(&c) == id {
+			if h := s.subtreeHeight(all, c.ID) + 1; h > height {
+				height = h
+			}
+		}
+	}
+	return height
+}
+
+// all 按同级顺序返回所有分类
+func (s *CategoryService) all() ([]model.Category, error) {
+	var list []model.Category
+	err := database.DB.Order("sort_order asc, id asc").Find(&list).Error
+	return list, err
+}
+
+// validateCategory 校验文章的分类是否存在，0 表示未分类
+func validateCategory(tx *gorm.DB, categoryID *uint) (*uint, error) {
+	if categoryID == nil || *categoryID == 0 {
+		return nil, nil
+	}
+	var count int64
+	if err := tx.Model(&model.Category{}).Where("id = ?", *categoryID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("category not found")
+	}
+	return categoryID, nil
+}
+
+func parentOf(c *model.Category) uint {
+	if c.ParentID == nil {
+		return 0
+	}
+	return *c.ParentID
+}
+
+func sumPostCounts(c *model.Category) int64 {
+	for _, child := range c.Children {
+		c.PostCount += sumPostCounts(child)
+	}
+	return c.PostCount
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const maxCollectionItems = 1000
+
+// CollectionService 收藏夹。放入收藏夹的文章同时会被收藏，收藏数仍以 user_favorites 为准
+type CollectionService struct{}
+
+var collectionService = new(CollectionService)
+
+// CreateCollection 创建收藏夹
+func (s *CollectionService) CreateCollection(userID uint, name, description string, isPublic bool) (*model.Collection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	collection := model.Collection{UserID: userID, Name: name, Description: description, IsPublic: isPublic}
+	if err := database.DB.Create(&collection).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// UpdateCollection 修改收藏夹名称、简介和公开状态
+func (s *CollectionService) UpdateCollection(userID, id uint, name, description string, isPublic bool) (*model.Collection, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	collection, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	collection.Name = name
+	collection.Description = description
+	collection.IsPublic = isPublic
+	err = database.DB.Model(collection).Select("name", "description", "is_public").Updates(collection).Error
+	return collection, err
+}
+
+// DeleteCollection 删除收藏夹，其中的文章仍保留在收藏中
+func (s *CollectionService) DeleteCollection(userID, id uint) error {
+	collection, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", collection.ID).Delete(&model.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(collection).Error
+	})
+}
+
+// ListUserCollections 列出用户的收藏夹，非本人查看时只返回公开的收藏夹
+func (s *CollectionService) ListUserCollections(userID uint, viewer Actor) ([]model.Collection, error) {
+	var list []model.Collection
+	db := database.DB.Model(&model.Collection{}).
+		Select("collections.*, (SELECT COUNT(*) FROM collection_items WHERE collection_items.collection_id = collections.id) as item_count").
+		Where("user_id = ?", userID)
+	if userID != viewer.UserID {
+		db = db.Where("is_public = ?", true)
+	}
+	err := db.Order("created_at desc").Find(&list).Error
+	return list, err
+}
+
+// GetCollection 获取收藏夹及其中的文章。私密收藏夹只有本人可见，
+// 收藏夹中 viewer 无权查看的文章（如私密账号的文章）不会返回
+func (s *CollectionService) GetCollection(id uint, viewer Actor) (*model.Collection, error) {
+	var collection model.Collection
+	if err := database.DB.Preload("User").First(&collection, id).Error; err != nil {
+		return nil, errors.New("collection not found")
+	}
+	if !collection.IsPublic && collection.UserID != viewer.UserID {
+		return nil, errors.New("collection not found")
+	}
+
+	db := database.DB.Model(&model.CollectionItem{}).
+		Joins("JOIN posts ON posts.id = collection_items.post_id AND posts.deleted_at IS NULL").
+		Where("collection_items.collection_id = ?", collection.ID)
+	err := visiblePosts(db, viewer).
+		Preload("Post").Preload("Post.User").Preload("Post.Tags").
+		Order("collection_items.position asc, collection_items.id asc").
+		Find(&collection.Items).Error
+	if err != nil {
+		return nil, err
+	}
+	collection.ItemCount = len(collection.Items)
+	return &collection, nil
+}
+
+// AddItem 将文章放入收藏夹，尚未收藏的文章会同时被收藏。已在收藏夹中时更新备注
+func (s *CollectionService) AddItem(userID, id, postID uint, note string) (*model.CollectionItem, error) {
+	collection, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	var post model.Post
+	if err := database.DB.Select("id", "user_id").First(&post, postID).Error; err != nil || !canViewAuthor(post.UserID, Actor{UserID: userID}) {
+		return nil, errors.New("post not found")
+	}
+
+	var item model.CollectionItem
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ? AND post_id = ?", collection.ID, postID).First(&item).Error; err == nil {
+			return tx.Model(&item).Update("note", note).Error
+		}
+
+		var count int64
+		tx.Model(&model.CollectionItem{}).Where("collection_id = ?", collection.ID).Count(&count)
+		if count >= maxCollectionItems {
+			return errors.New("collection is full")
+		}
+		// 新文章排在最后
+		var last int
+		tx.Model(&model.CollectionItem{}).Where("collection_id = ?", collection.ID).
+			Select("COALESCE(MAX(position), 0)").Scan(&last)
+
+		item = model.CollectionItem{CollectionID: collection.ID, PostID: postID, Note: note, Position: last + 1}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return tx.Exec("INSERT INTO user_favorites (user_id, post_id) SELECT ?, ? FROM DUAL "+
+			"WHERE NOT EXISTS (SELECT 1 FROM user_favorites WHERE user_id = ? AND post_id = ?)",
+			userID, postID, userID, postID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// UpdateItemNote 修改收藏夹中文章的备注
+func (s *CollectionService) UpdateItemNote(userID, id, postID uint, note string) error {
+	collection, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	result := database.DB.Model(&model.CollectionItem{}).
+		Where("collection_id = ? AND post_id = ?", collection.ID, postID).
+		Update("note", note)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("item not found")
+	}
+	return nil
+}
+
+// RemoveItem 从收藏夹中移除文章，文章仍保留在收藏中
+func (s *CollectionService) RemoveItem(userID, id, postID uint) error {
+	collection, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	return database.DB.Where("collection_id = ? AND post_id = ?", collection.ID, postID).
+		Delete(&model.CollectionItem{}).Error
+}
+
+// ReorderItems 按 postIDs 的顺序排列收藏夹中的文章，未列出的文章排在最后
+func (s *CollectionService) ReorderItems(userID, id uint, postIDs []uint) error {
+	collection, err := s.owned(userID, id)
+	if err != nil {
+		return err
+	}
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var items []model.CollectionItem
+		if err := tx.Where("collection_id = ?", collection.ID).Order("position asc, id asc").Find(&items).Error; err != nil {
+			return err
+		}
+
+		order := make(map[uint]int, len(postIDs))
+		for i, postID := range postIDs {
+			if _, ok := order[postID]; !ok {
+				order[postID] = i + 1
+			}
+		}
+		next := len(postIDs) + 1
+		for _, item := range items {
+			position, ok := order[item.PostID]
+			if !ok {
+				position = next
+				next++
+			}
+			if err := tx.Model(&item).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// removeFavorite 取消收藏时同时从该用户的所有收藏夹中移除文章
+func (s *CollectionService) removeFavorite(tx *gorm.DB, userID, postID uint) error {
+	return tx.Where("post_id = ? AND collection_id IN (SELECT id FROM collections WHERE user_id = ?)", postID, userID).
+		Delete(&model.CollectionItem{}).Error
+}
+
+func (s *CollectionService) owned(userID, id uint) (*model.Collection, error) {
+	var collection model.Collection
+	if err := database.DB.Where("id = ? AND user_id = ?", id, userID).First(&collection).Error; err != nil {
+		return nil, errors.New("collection not found")
+	}
+	return &collection, nil
+}
@@ -11,15 +11,22 @@ import (
 type CommentService struct{}
 
 func (s *CommentService) CreateComment(comment *model.Comment) error {
-	if err := database.DB.Create(comment).Error; err != nil {
-		return err
+	var post model.Post
+	if err := database.DB.First(&post, comment.PostID).Error; err != nil {
+		return errors.New("post not found")
+	}
+	// 无权查看私密账号文章的用户不能评论
+	if !canViewAuthor(post.UserID, Actor{UserID: comment.UserID}) {
+		return errors.New("post not found")
+	}
+	// 被文章作者屏蔽的用户不能评论
+	if blockService.HasBlocked(post.UserID, comment.UserID) {
+		return errors.New("blocked")
 	}
 
-	// 如果是回复评论
+	// 如果是回复评论，查找被回复的评论
+	var targetComment *model.Comment
 	if comment.ParentID != nil {
-		var targetComment model.Comment
-		// 查找被回复的评论
-		// targetID := comment.ID
 		var targetID uint
 		if comment.ReplyToID != nil {
 			targetID = *comment.ReplyToID
@@ -27,38 +34,57 @@ func (s *CommentService) CreateComment(comment *model.Comment) error {
 			targetID = *comment.ParentID
 		}
 
-		if err := database.DB.Preload("User").First(&targetComment, targetID).Error; err == nil {
-			// 通知被回复的人
-			if targetComment.UserID != comment.UserID {
-				notification := &model.Notification{
-					UserID:     targetComment.UserID,
-					Type:       "reply",
-					Content:    comment.Content,
-					FromUserID: comment.UserID,
-					PostID:     comment.PostID,
-					CommentID:  comment.ID,
-				}
-				database.DB.Create(notification)
+		var target model.Comment
+		if err := database.DB.First(&target, targetID).Error; err == nil {
+			// 被回复者屏蔽的用户不能回复
+			if blockService.HasBlocked(target.UserID, comment.UserID) {
+				return errors.New("blocked")
 			}
+			targetComment = &target
 		}
-	} else {
-		// 创建通知给文章作者
-		var post model.Post
-		if err := database.DB.First(&post, comment.PostID).Error; err == nil {
-			// 如果评论者不是作者本人，则发送通知
-			if post.UserID != comment.UserID {
-				notification := &model.Notification{
-					UserID:     post.UserID,
-					Type:       "comment",
-					Content:    comment.Content,
-					FromUserID: comment.UserID,
-					PostID:     comment.PostID,
-					CommentID:  comment.ID,
-				}
-				database.DB.Create(notification)
-			}
+	}
+
+	var mentioned []model.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
 		}
+		var err error
+		mentioned, err = mentionService.sync(tx, MentionSourceComment, comment.ID, comment.PostID, comment.UserID, comment.Content, true)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	// 已收到回复或评论通知的用户不再收到提及通知
+	notified := make(map[uint]bool)
+	if comment.ParentID != nil {
+		// 通知被回复的人
+		if targetComment != nil && targetComment.UserID != comment.UserID {
+			notified[targetComment.UserID] = true
+			notificationService.CreateNotification(&model.Notification{
+				UserID:     targetComment.UserID,
+				Type:       "reply",
+				Content:    comment.Content,
+				FromUserID: comment.UserID,
+				PostID:     comment.PostID,
+				CommentID:  comment.ID,
+			})
+		}
+	} else if post.UserID != comment.UserID {
+		// 如果评论者不是作者本人，则通知文章作者
+		notified[post.UserID] = true
+		notificationService.CreateNotification(&model.Notification{
+			UserID:     post.UserID,
+			Type:       "comment",
+			Content:    comment.Content,
+			FromUserID: comment.UserID,
+			PostID:     comment.PostID,
+			CommentID:  comment.ID,
+		})
 	}
+	mentionService.notify(mentioned, comment.UserID, post.UserID, comment.PostID, comment.ID, comment.Content, notified)
 
 	return nil
 }
@@ -83,7 +109,7 @@ func (s *CommentService) GetCommentsByUser(userID uint) ([]model.Comment, error)
 	return comments, err
 }
 
-func (s *CommentService) DeleteComment(commentID uint, userID uint, userRole string) error {
+func (s *CommentService) DeleteComment(commentID uint, actor Actor) error {
 	var comment model.Comment
 	// Preload Post to check post author
 	if err := database.DB.Preload("Post").First(&comment, commentID).Error; err != nil {
@@ -91,20 +117,39 @@ func (s *CommentService) DeleteComment(commentID uint, userID uint, userRole str
 	}
 
 	// Logic: Allow delete if userID matches comment author OR if userID matches the post author OR if user is admin
-	if userRole == "admin" || comment.UserID == userID || (comment.Post != nil && comment.Post.UserID == userID) {
-		return database.DB.Delete(&comment).Error
+	if actor.IsAdmin() || comment.UserID == actor.UserID || (comment.Post != nil && comment.Post.UserID == actor.UserID) {
+		return database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&comment).Error; err != nil {
+				return err
+			}
+			if err := mentionService.removeSource(tx, MentionSourceComment, comment.ID); err != nil {
+				return err
+			}
+			snapshot := comment
+			snapshot.Post = nil
+			return auditService.record(tx, actor, "comment.delete", "comment", comment.ID, snapshot, nil)
+		})
 	}
 
 	return errors.New("unauthorized to delete this comment")
 }
 
-func (s *CommentService) GetCommentsByPostID(postID uint) ([]model.Comment, error) {
+// GetCommentsByPostID 获取文章的评论，viewer 屏蔽的用户的评论和回复不会出现。
+// viewer 无权查看私密账号的文章时返回 post not found
+func (s *CommentService) GetCommentsByPostID(postID uint, viewer Actor) ([]model.Comment, error) {
+	var post model.Post
+	if err := database.DB.Select("id", "user_id").First(&post, postID).Error; err != nil || !canViewAuthor(post.UserID, viewer) {
+		return nil, errors.New("post not found")
+	}
+
+	viewerID := viewer.UserID
 	var comments []model.Comment
 	// 只获取顶级评论（ParentID 为 nil），并预加载回复及其作者
-	err := database.DB.Where("post_id = ? AND parent_id IS NULL", postID).
+	db := database.DB.Where("post_id = ? AND parent_id IS NULL", postID)
+	err := excludeHiddenUsers(db, viewerID, "comments.user_id", BlockKindBlock).
 		Preload("User").
 		Preload("Replies", func(db *gorm.DB) *gorm.DB {
-			return db.Order("created_at asc")
+			return excludeHiddenUsers(db, viewerID, "comments.user_id", BlockKindBlock).Order("created_at asc")
 		}).
 		Preload("Replies.User").
 		Order("created_at desc").
@@ -125,6 +170,8 @@ func (s *CommentService) GetCommentsByPostID(postID uint) ([]model.Comment, erro
 			}
 		}
 	}
+	attachCommentReactions(comments, viewerID)
+	attachCommentMentions(comments)
 
 	return comments, nil
 }
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dataExportTTL          = 7 * 24 * time.Hour // 导出文件的保留时长
+	dataExportCooldown     = 24 * time.Hour     // 两次导出申请的最小间隔
+	dataExportPollInterval = 30 * time.Second
+	dataExportLease        = 30 * time.Minute // 生成中断（如进程重启）后允许重新领取的时间
+)
+
+// DataExportService 生成个人数据导出包
+type DataExportService struct{}
+
+var dataExportService = new(DataExportService)
+
+// StartDataExportWorker 启动后台导出任务
+func StartDataExportWorker() {
+	go func() {
+		ticker := time.NewTicker(dataExportPollInterval)
+		defer ticker.Stop()
+		for {
+			dataExportService.ProcessQueue()
+			<-ticker.C
+		}
+	}()
+}
+
+// RequestExport 申请导出个人数据，导出包由后台任务异步生成
+func (s *DataExportService) RequestExport(userID uint) (*model.DataExport, error) {
+	var recent model.DataExport
+	err := database.DB.Where("user_id = ? AND status <> ?", userID, "failed").Order("created_at desc").First(&recent).Error
+	if err == nil && time.Since(recent.CreatedAt) < dataExportCooldown {
+		return nil, errors.New("an export was requested recently, please try again later")
+	}
+
+	export := model.DataExport{
+		UserID: userID,
+		Status: "pending",
+	}
+	if err := database.DB.Create(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (s *DataExportService) ListExports(userID uint) ([]model.DataExport, error) {
+	var exports []model.DataExport
+	err := database.DB.Where("user_id = ?", userID).Order("created_at desc").Limit(20).Find(&exports).Error
+	return exports, err
+}
+
+// GetExportFile 返回可下载的导出文件路径和下载文件名
+func (s *DataExportService) GetExportFile(userID, exportID uint) (string, string, error) {
+	var export model.DataExport
+	if err := database.DB.Where("id = ? AND user_id = ?", exportID, userID).First(&export).Error; err != nil {
+		return "", "", errors.New("export not found")
+	}
+	if export.Status != "ready" || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return "", "", errors.New("export is not available")
+	}
+	filename := fmt.Sprintf("simple-blog-export-%s.zip", export.CreatedAt.Format("20060102"))
+	return export.FilePath, filename, nil
+}
+
+// ProcessQueue 清理过期的导出文件并生成待处理的导出包
+func (s *DataExportService) ProcessQueue() {
+	now := time.Now()
+
+	var expired []model.DataExport
+	database.DB.Where("status = ? AND expires_at < ?", "ready", now).Find(&expired)
+	for _, export := range expired {
+		os.Remove(export.FilePath)
+		database.DB.Model(&model.DataExport{}).Where("id = ?", export.ID).Update("status", "expired")
+	}
+
+	var pending []model.DataExport
+	err := database.DB.
+		Where("status = ? OR (status = ? AND started_at < ?)", "pending", "processing", now.Add(-dataExportLease)).
+		Order("created_at asc").
+		Limit(5).
+		Find(&pending).Error
+	if err != nil {
+		fmt.Printf("DataExportService: failed to load exports: %v\n", err)
+		return
+	}
+
+	for i := range pending {
+		// 条件更新领取任务，多实例部署时同一个导出只会被生成一次
+		result := database.DB.Model(&model.DataExport{}).
+			Where("id = ? AND status = ? AND updated_at = ?", pending[i].ID, pending[i].Status, pending[i].UpdatedAt).
+			Updates(map[string]interface{}{
+				"status":     "processing",
+				"started_at": now,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		s.generate(&pending[i])
+	}
+}
+
+func (s *DataExportService) generate(export *model.DataExport) {
+	path, size, err := s.buildArchive(export)
+	if err != nil {
+		fmt.Printf("DataExportService: export %d failed: %v\n", export.ID, err)
+		database.DB.Model(&model.DataExport{}).Where("id = ?", export.ID).Updates(map[string]interface{}{
+			"status": "failed",
+			"error":  truncate(err.Error(), 1000),
+		})
+		return
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(dataExportTTL)
+	database.DB.Model(&model.DataExport{}).Where("id = ?", export.ID).Updates(map[string]interface{}{
+		"status":       "ready",
+		"file_path":    path,
+		"file_size":    size,
+		"completed_at": now,
+		"expires_at":   expiresAt,
+	})
+
+	var user model.User
+	if err := database.DB.First(&user, export.UserID).Error; err != nil {
+		return
+	}
+	notificationService.CreateNotification(&model.Notification{
+		UserID:  user.ID,
+		Type:    "data_export",
+		Content: "您的个人数据导出已生成，可在账号设置中下载",
+	})
+	if user.Email != "" {
+		err := mailService.Enqueue(user.Email, "data_export_ready", user.Locale, map[string]interface{}{
+			"Username":  user.Username,
+			"Link":      config.GetAppConfig().BaseURL + "/settings",
+			"ExpiresAt": expiresAt.Format("2006-01-02 15:04:05"),
+		})
+		if err != nil {
+			fmt.Printf("DataExportService: failed to enqueue mail: %v\n", err)
+		}
+	}
+}
+
+// buildArchive 将用户数据写入 ZIP 文件，先写临时文件，完成后再重命名
+func (s *DataExportService) buildArchive(export *model.DataExport) (string, int64, error) {
+	dir := config.GetAppConfig().ExportDir
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", 0, err
+	}
+	suffix, err := randomToken(8)
+	if err != nil {
+		return "", 0, err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d_%d_%s.zip", export.UserID, export.ID, suffix))
+
+	tmp, err := os.CreateTemp(dir, "export-*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := zip.NewWriter(tmp)
+	if err := s.writeUserData(zw, export.UserID); err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return "", 0, err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	return path, info.Size(), nil
+}
+
+type exportPostRef struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+type exportUserRef struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+type exportCollection struct {
+	ID          uint                   `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	IsPublic    bool                   `json:"is_public"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Items       []exportCollectionItem `json:"items"` // 按收藏夹中的顺序
+}
+
+type exportCollectionItem struct {
+	PostID    uint      `json:"post_id"`
+	Title     string    `json:"title"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type exportConversation struct {
+	ID            uint      `json:"id"`
+	PeerID        uint      `json:"peer_id"`
+	PeerUsername  string    `json:"peer_username"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	LastReadID    uint      `json:"last_read_id"`
+	Muted         bool      `json:"muted"`
+}
+
+type exportSeries struct {
+	ID          uint            `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Posts       []exportPostRef `json:"posts"` // 按系列中的顺序
+}
+
+func (s *DataExportService) writeUserData(zw *zip.Writer, userID uint) error {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if err := writeZipJSON(zw, "profile.json", user); err != nil {
+		return err
+	}
+
+	var identities []model.UserIdentity
+	database.DB.Where("user_id = ?", userID).Find(&identities)
+	if err := writeZipJSON(zw, "identities.json", identities); err != nil {
+		return err
+	}
+
+	var posts []model.Post
+	// 直接转发没有自己的内容，不导出为文章
+	err := withoutPlainReposts(database.DB.Preload("Tags").Where("user_id = ?", userID)).
+		Order("created_at asc").Find(&posts).Error
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		w, err := zw.Create(fmt.Sprintf("posts/%d.md", post.ID))
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, postMarkdown(&post)); err != nil {
+			return err
+		}
+	}
+
+	var comments []model.Comment
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&comments).Error; err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, "comments.json", comments); err != nil {
+		return err
+	}
+
+	var seriesList []model.Series
+	database.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&seriesList)
+	exportedSeries := make([]exportSeries, 0, len(seriesList))
+	for _, series := range seriesList {
+		item := exportSeries{ID: series.ID, Title: series.Title, Description: series.Description, CreatedAt: series.CreatedAt}
+		database.DB.Table("posts").Select("posts.id, posts.title").
+			Joins("JOIN series_posts ON series_posts.post_id = posts.id").
+			Where("series_posts.series_id = ? AND posts.deleted_at IS NULL", series.ID).
+			Order("series_posts.position asc").Scan(&item.Posts)
+		exportedSeries = append(exportedSeries, item)
+	}
+	if err := writeZipJSON(zw, "series.json", exportedSeries); err != nil {
+		return err
+	}
+
+	var likes, favorites []exportPostRef
+	database.DB.Table("posts").Select("posts.id, posts.title").
+		Joins("JOIN user_likes ON user_likes.post_id = posts.id").
+		Where("user_likes.user_id = ?", userID).Scan(&likes)
+	database.DB.Table("posts").Select("posts.id, posts.title").
+		Joins("JOIN user_favorites ON user_favorites.post_id = posts.id").
+		Where("user_favorites.user_id = ?", userID).Scan(&favorites)
+	if err := writeZipJSON(zw, "likes.json", likes); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, "favorites.json", favorites); err != nil {
+		return err
+	}
+
+	var reactions []model.Reaction
+	database.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&reactions)
+	if err := writeZipJSON(zw, "reactions.json", reactions); err != nil {
+		return err
+	}
+
+	var collections []model.Collection
+	database.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&collections)
+	exportedCollections := make([]exportCollection, 0, len(collections))
+	for _, collection := range collections {
+		item := exportCollection{
+			ID:          collection.ID,
+			Name:        collection.Name,
+			Description: collection.Description,
+			IsPublic:    collection.IsPublic,
+			CreatedAt:   collection.CreatedAt,
+		}
+		database.DB.Table("collection_items").
+			Select("collection_items.post_id, posts.title, collection_items.note, collection_items.created_at").
+			Joins("LEFT JOIN posts ON posts.id = collection_items.post_id").
+			Where("collection_items.collection_id = ?", collection.ID).
+			Order("collection_items.position asc").Scan(&item.Items)
+		exportedCollections = append(exportedCollections, item)
+	}
+	if err := writeZipJSON(zw, "collections.json", exportedCollections); err != nil {
+		return err
+	}
+
+	var following, followers []exportUserRef
+	database.DB.Table("users").Select("users.id, users.username").
+		Joins("JOIN user_followers ON user_followers.followed_id = users.id").
+		Where("user_followers.follower_id = ?", userID).Scan(&following)
+	database.DB.Table("users").Select("users.id, users.username").
+		Joins("JOIN user_followers ON user_followers.follower_id = users.id").
+		Where("user_followers.followed_id = ?", userID).Scan(&followers)
+	if err := writeZipJSON(zw, "following.json", following); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, "followers.json", followers); err != nil {
+		return err
+	}
+
+	var followedTags []struct {
+		ID   uint   `json:"id"`
+		Name string `json:"name"`
+	}
+	database.DB.Table("tags").Select("tags.id, tags.name").
+		Joins("JOIN user_tag_follows ON user_tag_follows.tag_id = tags.id").
+		Where("user_tag_follows.user_id = ?", userID).Scan(&followedTags)
+	if err := writeZipJSON(zw, "followed_tags.json", followedTags); err != nil {
+		return err
+	}
+
+	var blocks []struct {
+		ID       uint   `json:"id"`
+		Username string `json:"username"`
+		Kind     string `json:"kind"`
+	}
+	database.DB.Table("users").Select("users.id, users.username, user_blocks.kind").
+		Joins("JOIN user_blocks ON user_blocks.blocked_id = users.id").
+		Where("user_blocks.user_id = ?", userID).Scan(&blocks)
+	if err := writeZipJSON(zw, "blocks.json", blocks); err != nil {
+		return err
+	}
+
+	// 私信导出用户参与的所有会话，包括对方发送的私信
+	var conversations []exportConversation
+	database.DB.Table("conversations").
+		Select("conversations.id, users.id as peer_id, users.username as peer_username, conversations.created_at, "+
+			"conversations.last_message_at, conversation_members.last_read_id, conversation_members.muted").
+		Joins("JOIN conversation_members ON conversation_members.conversation_id = conversations.id AND conversation_members.user_id = ?", userID).
+		Joins("LEFT JOIN users ON users.id = CASE WHEN conversations.user_a_id = ? THEN conversations.user_b_id ELSE conversations.user_a_id END", userID).
+		Order("conversations.created_at asc").Scan(&conversations)
+	if err := writeZipJSON(zw, "conversations.json", conversations); err != nil {
+		return err
+	}
+	var messages []struct {
+		ID             uint      `json:"id"`
+		ConversationID uint      `json:"conversation_id"`
+		SenderID       uint      `json:"sender_id"`
+		Content        string    `json:"content"`
+		CreatedAt      time.Time `json:"created_at"`
+	}
+	database.DB.Table("messages").Select("id, conversation_id, sender_id, content, created_at").
+		Where("conversation_id IN (SELECT conversation_id FROM conversation_members WHERE user_id = ?)", userID).
+		Order("conversation_id asc, id asc").Scan(&messages)
+	if err := writeZipJSON(zw, "messages.json", messages); err != nil {
+		return err
+	}
+
+	var notifications []model.Notification
+	database.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&notifications)
+	if err := writeZipJSON(zw, "notifications.json", notifications); err != nil {
+		return err
+	}
+
+	return writeUserMedia(zw, userID)
+}
+
+// writeUserMedia 打包用户上传的头像和文章图片，上传文件名以用户ID开头
+func writeUserMedia(zw *zip.Writer, userID uint) error {
+	uploadDir := config.GetAppConfig().UploadDir
+	for _, sub := range []string{"avatars", "posts"} {
+		files, _ := filepath.Glob(filepath.Join(uploadDir, sub, fmt.Sprintf("%d_*", userID)))
+		for _, file := range files {
+			if err := copyToZip(zw, "media/"+sub+"/"+filepath.Base(file), file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func copyToZip(zw *zip.Writer, name, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// postMarkdown 将文章导出为带 front matter 的 Markdown
+func postMarkdown(post *model.Post) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	b.WriteString("title: " + strconv.Quote(post.Title) + "\n")
+	b.WriteString("date: " + post.CreatedAt.Format(time.RFC3339) + "\n")
+	b.WriteString("status: " + post.Status + "\n")
+	if len(post.Tags) > 0 {
+		names := make([]string, len(post.Tags))
+		for i, tag := range post.Tags {
+			names[i] = strconv.Quote(tag.Name)
+		}
+		b.WriteString("tags: [" + strings.Join(names, ", ") + "]\n")
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(post.Content)
+	b.WriteString("\n")
+	return b.String()
+}
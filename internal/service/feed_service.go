@@ -0,0 +1,127 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"time"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 50
+)
+
+// FeedCursor 时间线的翻页位置，按 (created_at, id) 倒序排列
+type FeedCursor struct {
+	CreatedAt time.Time
+	ID        uint // 文章 ID；私信和会话列表复用该游标时为对应记录的 ID
+}
+
+// Encode 编码为不透明的字符串，供客户端原样传回
+func (c FeedCursor) Encode() string {
+	raw := fmt.Sprintf("%d:%d", c.CreatedAt.UnixNano(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseFeedCursor 解析客户端传回的游标，空字符串表示从最新开始
+func ParseFeedCursor(s string) (*FeedCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var nanos int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	return &FeedCursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// FeedSource 时间线的数据来源。当前为读时合并（fan-out-on-read），
+// 之后可以替换为发布时写入收件箱的实现，调用方无需改动
+type FeedSource interface {
+	// Timeline 返回游标之后最多 limit 篇文章，按时间倒序且不重复
+	Timeline(userID uint, cursor *FeedCursor, limit int) ([]model.Post, error)
+}
+
+// FanOutOnReadFeed 读取时实时查询关注的作者和标签的文章
+type FanOutOnReadFeed struct{}
+
+func (FanOutOnReadFeed) Timeline(userID uint, cursor *FeedCursor, limit int) ([]model.Post, error) {
+	var posts []model.Post
+
+	// 用 IN 子查询组合两类来源，同时关注作者和标签的文章只会出现一次
+	db := database.DB.Model(&model.Post{}).
+		Select("posts.*, "+
+			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, "+
+			"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.post_id = posts.id) as favorite_count, "+
+			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count, "+
+			repostCountColumn).
+		Where("posts.status = ?", "published").
+		Where("(posts.user_id IN (SELECT followed_id FROM user_followers WHERE follower_id = ?) "+
+			"OR posts.id IN (SELECT post_tags.post_id FROM post_tags "+
+			"JOIN user_tag_follows ON user_tag_follows.tag_id = post_tags.tag_id WHERE user_tag_follows.user_id = ?))",
+			userID, userID)
+	db = excludeHiddenUsers(db, userID, "posts.user_id", BlockKindBlock, BlockKindMute)
+	db = withoutOrphanReposts(db, Actor{UserID: userID})
+	// 通过标签关注到的私密账号文章同样需要是其关注者才能看到
+	db = visiblePosts(db, Actor{UserID: userID})
+	if cursor != nil {
+		db = db.Where("(posts.created_at < ? OR (posts.created_at = ? AND posts.id < ?))",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+
+	err := db.Preload("User").Preload("Tags").Preload("Category").
+		Order("posts.created_at DESC, posts.id DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// FeedService 个人时间线：关注的作者和标签的文章，不含屏蔽和静音的用户
+type FeedService struct {
+	source FeedSource
+}
+
+var feedService = &FeedService{source: FanOutOnReadFeed{}}
+
+// NewFeedService 创建使用默认数据来源的时间线服务
+func NewFeedService() *FeedService {
+	return feedService
+}
+
+// Timeline 返回一页时间线以及下一页的游标，没有更多内容时游标为空
+func (s *FeedService) Timeline(userID uint, cursor string, limit int) ([]model.Post, string, error) {
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if limit > maxFeedLimit {
+		limit = maxFeedLimit
+	}
+	after, err := ParseFeedCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// 多取一条用于判断是否还有下一页
+	posts, err := s.source.Timeline(userID, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[len(posts)-1]
+		next = FeedCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	attachPostReactions(posts, userID)
+	attachPostMentions(posts)
+	attachReposts(posts, Actor{UserID: userID})
+	return posts, next, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FollowRequestService 私密账号的关注请求
+type FollowRequestService struct{}
+
+var followRequestService = new(FollowRequestService)
+
+// ListIncoming 列出用户收到的待处理关注请求
+func (s *FollowRequestService) ListIncoming(userID uint) ([]model.FollowRequest, error) {
+	var requests []model.FollowRequest
+	err := database.DB.Preload("Follower").
+		Where("followed_id = ?", userID).
+		Order("created_at desc").
+		Find(&requests).Error
+	return requests, err
+}
+
+// Approve 通过关注请求，并通知请求者
+func (s *FollowRequestService) Approve(userID, requestID uint) error {
+	request, err := s.find(userID, requestID)
+	if err != nil {
+		return err
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return s.accept(tx, request)
+	})
+	if err != nil {
+		return err
+	}
+
+	notificationService.CreateNotification(&model.Notification{
+		UserID:     request.FollowerID,
+		Type:       "follow_accepted",
+		FromUserID: userID,
+	})
+	return nil
+}
+
+// Reject 拒绝关注请求，请求者不会收到通知
+func (s *FollowRequestService) Reject(userID, requestID uint) error {
+	request, err := s.find(userID, requestID)
+	if err != nil {
+		return err
+	}
+	return database.DB.Delete(request).Error
+}
+
+// create 创建关注请求并通知对方，重复请求不会再次通知
+func (s *FollowRequestService) create(followerID, followedID uint) error {
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.FollowRequest{FollowerID: followerID, FollowedID: followedID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		notificationService.CreateNotification(&model.Notification{
+			UserID:     followedID,
+			Type:       "follow_request",
+			FromUserID: followerID,
+		})
+	}
+	return nil
+}
+
+// approveAll 通过用户所有待处理的关注请求，用于私密账号改为公开时
+func (s *FollowRequestService) approveAll(tx *gorm.DB, userID uint) error {
+	var requests []model.FollowRequest
+	if err := tx.Where("followed_id = ?", userID).Find(&requests).Error; err != nil {
+		return err
+	}
+	for i := range requests {
+		if err := s.accept(tx, &requests[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FollowRequestService) accept(tx *gorm.DB, request *model.FollowRequest) error {
+	err := tx.Exec("INSERT INTO user_followers (follower_id, followed_id) SELECT ?, ? FROM DUAL "+
+		"WHERE NOT EXISTS (SELECT 1 FROM user_followers WHERE follower_id = ? AND followed_id = ?)",
+		request.FollowerID, request.FollowedID, request.FollowerID, request.FollowedID).Error
+	if err != nil {
+		return err
+	}
+	return tx.Delete(request).Error
+}
+
+func (s *FollowRequestService) find(userID, requestID uint) (*model.FollowRequest, error) {
+	var request model.FollowRequest
+	if err := database.DB.Where("id = ? AND followed_id = ?", requestID, userID).First(&request).Error; err != nil {
+		return nil, errors.New("follow request not found")
+	}
+	return &request, nil
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	hotRankingInterval = 10 * time.Minute
+	hotRankingMaxAge   = 30 * 24 * time.Hour // 更早的文章热度已衰减到可以忽略，不再计算
+	defaultHotWindow   = "7d"
+
+	hotRankingBatchSize = 500
+	hotScoreEpsilon     = 1e-9 // 变化小于该值的热度不重新写入
+)
+
+// hotWindows 热门文章和热门标签支持的时间窗口
+var hotWindows = map[string]time.Duration{
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// parseHotWindow 解析时间窗口，空字符串使用默认的 7d
+func parseHotWindow(window string) (time.Duration, error) {
+	if window == "" {
+		window = defaultHotWindow
+	}
+	d, ok := hotWindows[window]
+	if !ok {
+		return 0, errors.New("invalid window")
+	}
+	return d, nil
+}
+
+// HotRankingService 按时间衰减计算文章热度，结果保存在 posts.hot_score
+type HotRankingService struct{}
+
+var hotRankingService = new(HotRankingService)
+
+// StartHotRankingWorker 启动后台任务，定期重新计算文章热度
+func StartHotRankingWorker() {
+	go func() {
+		ticker := time.NewTicker(hotRankingInterval)
+		defer ticker.Stop()
+		for {
+			if err := hotRankingService.Recompute(); err != nil {
+				fmt.Printf("HotRankingService: failed to recompute hot scores: %v\n", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// hotWeights 热度公式的权重和衰减系数，来自站点设置
+type hotWeights struct {
+	like, comment, favorite, view, gravity float64
+}
+
+// hotScore 热度 = 加权互动数 / (发布小时数 + 2)^gravity
+func hotScore(w hotWeights, likes, comments, favorites, views int64, age time.Duration) float64 {
+	points := float64(likes)*w.like +
+		float64(comments)*w.comment +
+		float64(favorites)*w.favorite +
+		float64(views)*w.view
+	hours := math.Max(age.Hours(), 0)
+	return points / math.Pow(hours+2, w.gravity)
+}
+
+// Recompute 重新计算最近 30 天内发布的文章的热度，更早的文章热度清零。
+// 只写入热度有变化的文章，每批 hotRankingBatchSize 篇用一条 UPDATE 完成，不使用长事务
+func (s *HotRankingService) Recompute() error {
+	var rows []struct {
+		ID            uint
+		CreatedAt     time.Time
+		HotScore      float64
+		ViewCount     int64
+		LikeCount     int64
+		FavoriteCount int64
+		CommentCount  int64
+	}
+	since := time.Now().Add(-hotRankingMaxAge)
+	err := database.DB.Model(&model.Post{}).
+		Select("posts.id, posts.created_at, posts.hot_score, posts.view_count, "+
+			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, "+
+			"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.post_id = posts.id) as favorite_count, "+
+			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted_at IS NULL) as comment_count").
+		Where("posts.status = ? AND posts.created_at >= ?", "published", since).
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	weights := hotWeights{
+		like:     settingService.GetFloat(SettingHotLikeWeight),
+		comment:  settingService.GetFloat(SettingHotCommentWeight),
+		favorite: settingService.GetFloat(SettingHotFavoriteWeight),
+		view:     settingService.GetFloat(SettingHotViewWeight),
+		gravity:  settingService.GetFloat(SettingHotGravity),
+	}
+	now := time.Now()
+	var ids []uint
+	var args []interface{}
+	flush := func() error {
+		if len(ids) == 0 {
+			return nil
+		}
+		expr := "CASE id" + strings.Repeat(" WHEN ? THEN ?", len(ids)) + " END"
+		err := database.DB.Model(&model.Post{}).Where("id IN ?", ids).
+			UpdateColumn("hot_score", gorm.Expr(expr, args...)).Error
+		ids, args = ids[:0], args[:0]
+		return err
+	}
+	for _, row := range rows {
+		score := hotScore(weights, row.LikeCount, row.CommentCount, row.FavoriteCount, row.ViewCount, now.Sub(row.CreatedAt))
+		if math.Abs(score-row.HotScore) < hotScoreEpsilon {
+			continue
+		}
+		ids = append(ids, row.ID)
+		args = append(args, row.ID, score)
+		if len(ids) == hotRankingBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return database.DB.Model(&model.Post{}).
+		Where("created_at < ? AND hot_score <> ?", since, 0).
+		UpdateColumn("hot_score", 0).Error
+}
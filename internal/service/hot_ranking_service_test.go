@@ -0,0 +1,71 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHotScore(t *testing.T) {
+	weights := hotWeights{like: 1, comment: 3, favorite: 2, view: 0.1, gravity: 1.5}
+	tests := []struct {
+		name                              string
+		w                                 hotWeights
+		likes, comments, favorites, views int64
+		ageHours                          int64
+		want                              float64
+	}{
+		{"no interactions", weights, 0, 0, 0, 0, 0, 0},
+		{"new post", weights, 10, 2, 1, 100, 0, (10 + 6 + 2 + 10) / math.Pow(2, 1.5)},
+		{"one day old", weights, 10, 2, 1, 100, 24, (10 + 6 + 2 + 10) / math.Pow(26, 1.5)},
+		{"no decay", hotWeights{like: 1, gravity: 0}, 5, 0, 0, 0, 1000, 5},
+		{"future timestamp counts as new", weights, 1, 0, 0, 0, -5, 1 / math.Pow(2, 1.5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hotScore(tt.w, tt.likes, tt.comments, tt.favorites, tt.views, time.Duration(tt.ageHours)*time.Hour)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("hotScore = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHotScoreDecaysWithAge(t *testing.T) {
+	weights := hotWeights{like: 1, gravity: 1.8}
+	previous := math.Inf(1)
+	for _, hours := range []int{0, 1, 6, 24, 24 * 7} {
+		score := hotScore(weights, 100, 0, 0, 0, time.Duration(hours)*time.Hour)
+		if score >= previous {
+			t.Fatalf("score at %dh = %f, not lower than %f", hours, score, previous)
+		}
+		previous = score
+	}
+}
+
+func TestParseHotWindow(t *testing.T) {
+	tests := []struct {
+		window  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 7 * 24 * time.Hour, false},
+		{"24h", 24 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"30d", 30 * 24 * time.Hour, false},
+		{"1h", 0, true},
+		{"7D", 0, true},
+		{"all", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			got, err := parseHotWindow(tt.window)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHotWindow(%q) error = %v, wantErr %v", tt.window, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("parseHotWindow(%q) = %v, want %v", tt.window, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	userInviteTTL         = 7 * 24 * time.Hour
+	userInviteQuotaPeriod = 30 * 24 * time.Hour
+	maxInviteUses         = 1000
+
+	// 去掉容易混淆的 0/O、1/I
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	inviteCodeLength   = 10
+)
+
+// InviteService 邀请码的生成、吊销与使用
+type InviteService struct{}
+
+var inviteService = new(InviteService)
+
+// RegistrationMode 返回当前注册方式
+func (s *InviteService) RegistrationMode() string {
+	return settingService.Get(SettingRegistrationMode)
+}
+
+// CreateInvite 管理员生成邀请码，可设置使用次数和有效期
+func (s *InviteService) CreateInvite(actor Actor, maxUses int, expiresAt *time.Time, note string) (*model.InviteCode, error) {
+	if !actor.IsAdmin() {
+		return nil, errors.New("unauthorized")
+	}
+	if maxUses < 1 || maxUses > maxInviteUses {
+		return nil, errors.New("invalid max uses")
+	}
+	if expiresAt != nil && expiresAt.Before(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	invite := model.InviteCode{
+		CreatedBy: actor.UserID,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt,
+		Note:      note,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.create(tx, &invite); err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "invite.create", "invite_code", invite.ID, nil, invite)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// CreateUserInvite 普通用户生成一次性邀请码，受站点配额限制
+func (s *InviteService) CreateUserInvite(userID uint) (*model.InviteCode, error) {
+	quota := settingService.GetInt(SettingUserInviteQuota)
+	var count int64
+	database.DB.Model(&model.InviteCode{}).
+		Where("created_by = ? AND created_at > ?", userID, time.Now().Add(-userInviteQuotaPeriod)).
+		Count(&count)
+	if count >= int64(quota) {
+		return nil, errors.New("invite quota exceeded")
+	}
+
+	expiresAt := time.Now().Add(userInviteTTL)
+	invite := model.InviteCode{
+		CreatedBy: userID,
+		MaxUses:   1,
+		ExpiresAt: &expiresAt,
+	}
+	if err := s.create(database.DB, &invite); err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (s *InviteService) create(tx *gorm.DB, invite *model.InviteCode) error {
+	code, err := randomInviteCode()
+	if err != nil {
+		return err
+	}
+	invite.Code = code
+	return tx.Create(invite).Error
+}
+
+// ListInvites 分页获取邀请码，createdBy 为 0 时返回所有用户的邀请码
+func (s *InviteService) ListInvites(createdBy uint, page, pageSize int) ([]model.InviteCode, int64, error) {
+	var invites []model.InviteCode
+	var total int64
+
+	db := database.DB.Model(&model.InviteCode{})
+	if createdBy != 0 {
+		db = db.Where("created_by = ?", createdBy)
+	}
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := db.Order("created_at desc").Offset(offset).Limit(pageSize).Find(&invites).Error
+	return invites, total, err
+}
+
+// RevokeInvite 吊销邀请码，用户只能吊销自己生成的，管理员可以吊销任意邀请码
+func (s *InviteService) RevokeInvite(id uint, actor Actor) error {
+	var invite model.InviteCode
+	if err := database.DB.First(&invite, id).Error; err != nil {
+		return errors.New("invite not found")
+	}
+	if invite.CreatedBy != actor.UserID && !actor.IsAdmin() {
+		return errors.New("invite not found")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&invite).Error; err != nil {
+			return err
+		}
+		if invite.CreatedBy != actor.UserID {
+			return auditService.record(tx, actor, "invite.revoke", "invite_code", invite.ID, invite, nil)
+		}
+		return nil
+	})
+}
+
+// consumeInvite 在注册事务中使用邀请码，条件更新保证并发注册时不会超出使用次数
+func (s *InviteService) consumeInvite(tx *gorm.DB, code string) (*model.InviteCode, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return nil, errors.New("invite code required")
+	}
+
+	var invite model.InviteCode
+	if err := tx.Where("code = ?", code).First(&invite).Error; err != nil {
+		return nil, errors.New("invalid invite code")
+	}
+	result := tx.Model(&model.InviteCode{}).
+		Where("id = ? AND used_count < max_uses AND (expires_at IS NULL OR expires_at > ?)", invite.ID, time.Now()).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("invite code is expired or used up")
+	}
+	return &invite, nil
+}
+
+func randomInviteCode() (string, error) {
+	b := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = inviteCodeAlphabet[int(b[i])%len(inviteCodeAlphabet)]
+	}
+	return string(b), nil
+}
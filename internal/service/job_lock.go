@@ -0,0 +1,33 @@
+package service
+
+import (
+	"fmt"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// tryJobLock 尝试获取名为 name 的任务锁，成功后 ttl 内其他实例无法获取。
+// 锁不主动释放，到期后任一实例都可以再次获取，因此每个周期任务只执行一次
+func tryJobLock(name string, ttl time.Duration) bool {
+	now := time.Now()
+	result := database.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.JobLock{Name: name, LockedUntil: now.Add(ttl)})
+	if result.Error != nil {
+		fmt.Printf("JobLock: failed to acquire %s: %v\n", name, result.Error)
+		return false
+	}
+	if result.RowsAffected == 1 {
+		return true
+	}
+	result = database.DB.Model(&model.JobLock{}).
+		Where("name = ? AND locked_until <= ?", name, now).
+		Update("locked_until", now.Add(ttl))
+	if result.Error != nil {
+		fmt.Printf("JobLock: failed to acquire %s: %v\n", name, result.Error)
+		return false
+	}
+	return result.RowsAffected == 1
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttemptState 某个用户名或 IP 的登录失败状态
+type AttemptState struct {
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  time.Time
+}
+
+// LoginAttemptStore 登录失败计数的存储。内存实现适用于单实例，数据库实现可在多实例间共享
+type LoginAttemptStore interface {
+	Get(key string) (AttemptState, error)
+	// Increment 原子地增加失败次数；距上次失败超过 window 时从 1 重新计数
+	Increment(key string, now time.Time, window time.Duration) (AttemptState, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
+
+// 进程内共享的内存存储，保证登录和管理员解锁看到的是同一份计数
+var sharedMemoryAttemptStore = NewMemoryAttemptStore()
+
+// NewLoginAttemptStore 根据配置创建存储：memory 或 database（默认）
+func NewLoginAttemptStore(kind string) LoginAttemptStore {
+	if kind == "memory" {
+		return sharedMemoryAttemptStore
+	}
+	return &DBAttemptStore{}
+}
+
+// MemoryAttemptStore 进程内存储
+type MemoryAttemptStore struct {
+	mu      sync.Mutex
+	entries map[string]AttemptState
+}
+
+// 内存存储条目超过该数量时清理过期条目
+const memoryAttemptPruneSize = 10000
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{entries: make(map[string]AttemptState)}
+}
+
+func (m *MemoryAttemptStore) Get(key string) (AttemptState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.entries[key], nil
+}
+
+func (m *MemoryAttemptStore) Increment(key string, now time.Time, window time.Duration) (AttemptState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.entries) > memoryAttemptPruneSize {
+		for k, state := range m.entries {
+			if now.Sub(state.LastFailedAt) > window && now.After(state.LockedUntil) {
+				delete(m.entries, k)
+			}
+		}
+	}
+
+	state := m.entries[key]
+	if now.Sub(state.LastFailedAt) > window {
+		state.Failures = 0
+	}
+	state.Failures++
+	state.LastFailedAt = now
+	m.entries[key] = state
+	return state, nil
+}
+
+func (m *MemoryAttemptStore) Lock(key string, until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := m.entries[key]
+	state.LockedUntil = until
+	m.entries[key] = state
+	return nil
+}
+
+func (m *MemoryAttemptStore) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// DBAttemptStore 基于数据库的存储，使用行锁保证多实例下计数准确
+type DBAttemptStore struct{}
+
+func (d *DBAttemptStore) Get(key string) (AttemptState, error) {
+	var attempt model.LoginAttempt
+	err := database.DB.Where("attempt_key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return AttemptState{}, nil
+	}
+	if err != nil {
+		return AttemptState{}, err
+	}
+	return attemptState(&attempt), nil
+}
+
+func (d *DBAttemptStore) Increment(key string, now time.Time, window time.Duration) (AttemptState, error) {
+	var state AttemptState
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 时间字段使用当前时间初始化，避免写入 MySQL 严格模式不允许的零值日期
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&model.LoginAttempt{AttemptKey: key, LastFailedAt: now, LockedUntil: now}).Error; err != nil {
+			return err
+		}
+
+		var attempt model.LoginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("attempt_key = ?", key).First(&attempt).Error; err != nil {
+			return err
+		}
+
+		if now.Sub(attempt.LastFailedAt) > window {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailedAt = now
+		if err := tx.Save(&attempt).Error; err != nil {
+			return err
+		}
+		state = attemptState(&attempt)
+		return nil
+	})
+	return state, err
+}
+
+func (d *DBAttemptStore) Lock(key string, until time.Time) error {
+	return database.DB.Model(&model.LoginAttempt{}).
+		Where("attempt_key = ?", key).
+		Update("locked_until", until).Error
+}
+
+func (d *DBAttemptStore) Reset(key string) error {
+	return database.DB.Where("attempt_key = ?", key).Delete(&model.LoginAttempt{}).Error
+}
+
+func attemptState(attempt *model.LoginAttempt) AttemptState {
+	return AttemptState{
+		Failures:     attempt.Failures,
+		LastFailedAt: attempt.LastFailedAt,
+		LockedUntil:  attempt.LockedUntil,
+	}
+}
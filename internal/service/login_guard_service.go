@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+	"time"
+)
+
+const (
+	loginUserThreshold = 5              // 同一用户名允许连续失败的次数
+	loginIPThreshold   = 20             // 同一 IP 允许连续失败的次数
+	loginBaseLockout   = time.Minute    // 首次锁定时长，之后每次失败翻倍
+	loginMaxLockout    = time.Hour      // 最长锁定时长
+	loginAttemptWindow = 24 * time.Hour // 超过该时间没有失败记录则重新计数
+
+	loginUserCaptchaThreshold = 3 // 同一用户名失败达到该次数后登录需要验证码
+	loginIPCaptchaThreshold   = 5 // 同一 IP 失败达到该次数后登录需要验证码
+)
+
+// LoginLockedError 登录因失败次数过多被暂时锁定
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, try again in %d seconds", int(e.RetryAfter.Seconds()+0.5))
+}
+
+// LoginGuardService 按用户名和 IP 统计登录失败次数，超过阈值后按指数退避临时锁定
+type LoginGuardService struct {
+	Store LoginAttemptStore
+}
+
+var loginGuardService = NewLoginGuardService()
+
+func NewLoginGuardService() *LoginGuardService {
+	return &LoginGuardService{
+		Store: NewLoginAttemptStore(config.GetAppConfig().LoginAttemptStore),
+	}
+}
+
+// Check 登录前检查用户名和 IP 是否处于锁定状态
+func (s *LoginGuardService) Check(username, ip string) error {
+	now := time.Now()
+	for _, key := range []string{userAttemptKey(username), ipAttemptKey(ip)} {
+		state, err := s.Store.Get(key)
+		if err != nil {
+			fmt.Printf("LoginGuard: failed to load %s: %v\n", key, err)
+			continue
+		}
+		if now.Before(state.LockedUntil) {
+			return &LoginLockedError{RetryAfter: state.LockedUntil.Sub(now)}
+		}
+	}
+	return nil
+}
+
+// CaptchaRequired 用户名或 IP 近期失败次数较多时，登录需要先通过验证码
+func (s *LoginGuardService) CaptchaRequired(username, ip string) bool {
+	now := time.Now()
+	thresholds := map[string]int{
+		userAttemptKey(username): loginUserCaptchaThreshold,
+		ipAttemptKey(ip):         loginIPCaptchaThreshold,
+	}
+	for key, threshold := range thresholds {
+		state, err := s.Store.Get(key)
+		if err != nil {
+			continue
+		}
+		if state.Failures >= threshold && now.Sub(state.LastFailedAt) < loginAttemptWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordFailure 记录一次失败的登录，达到阈值时锁定并通知用户
+func (s *LoginGuardService) RecordFailure(username, ip string) {
+	now := time.Now()
+
+	userKey := userAttemptKey(username)
+	if state, err := s.Store.Increment(userKey, now, loginAttemptWindow); err == nil {
+		if state.Failures >= loginUserThreshold {
+			until := now.Add(lockoutDuration(state.Failures - loginUserThreshold))
+			s.Store.Lock(userKey, until)
+			// 每轮计数只在首次锁定时通知，避免攻击者借此轰炸用户
+			if state.Failures == loginUserThreshold {
+				s.notifyLockout(username, ip, until)
+			}
+		}
+	} else {
+		fmt.Printf("LoginGuard: failed to record %s: %v\n", userKey, err)
+	}
+
+	ipKey := ipAttemptKey(ip)
+	if state, err := s.Store.Increment(ipKey, now, loginAttemptWindow); err == nil {
+		if state.Failures >= loginIPThreshold {
+			s.Store.Lock(ipKey, now.Add(lockoutDuration(state.Failures-loginIPThreshold)))
+		}
+	} else {
+		fmt.Printf("LoginGuard: failed to record %s: %v\n", ipKey, err)
+	}
+}
+
+// RecordSuccess 登录成功后清除该用户名的失败记录。
+// IP 的计数不清除，否则攻击者可以用自己的账号反复重置计数
+func (s *LoginGuardService) RecordSuccess(username string) {
+	s.Store.Reset(userAttemptKey(username))
+}
+
+// UnlockUser 管理员解除用户的登录锁定
+func (s *LoginGuardService) UnlockUser(userID uint, actor Actor) error {
+	if !actor.IsAdmin() {
+		return errors.New("unauthorized")
+	}
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if err := s.Store.Reset(userAttemptKey(user.Username)); err != nil {
+		return err
+	}
+	return auditService.Record(actor, "user.unlock", "user", user.ID, nil, nil)
+}
+
+// UnlockIP 管理员解除 IP 的登录锁定
+func (s *LoginGuardService) UnlockIP(ip string, actor Actor) error {
+	if !actor.IsAdmin() {
+		return errors.New("unauthorized")
+	}
+	if err := s.Store.Reset(ipAttemptKey(ip)); err != nil {
+		return err
+	}
+	return auditService.Record(actor, "ip.unlock", "ip", 0, nil, map[string]string{"ip": ip})
+}
+
+func (s *LoginGuardService) notifyLockout(username, ip string, until time.Time) {
+	var user model.User
+	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		return
+	}
+
+	notificationService.CreateNotification(&model.Notification{
+		UserID:  user.ID,
+		Type:    "security",
+		Content: fmt.Sprintf("您的账号因多次登录失败已被临时锁定（来源 IP：%s），如非本人操作请尽快修改密码", ip),
+	})
+
+	if user.Email != "" {
+		err := mailService.Enqueue(user.Email, "account_locked", user.Locale, map[string]interface{}{
+			"Username":    user.Username,
+			"IP":          ip,
+			"LockedUntil": until.Format("2006-01-02 15:04:05"),
+		})
+		if err != nil {
+			fmt.Printf("LoginGuard: failed to enqueue lockout mail: %v\n", err)
+		}
+	}
+}
+
+// lockoutDuration 指数退避：第 n 次超限锁定 base * 2^n，最长 loginMaxLockout
+func lockoutDuration(n int) time.Duration {
+	if n > 10 {
+		return loginMaxLockout
+	}
+	d := loginBaseLockout << n
+	if d > loginMaxLockout {
+		return loginMaxLockout
+	}
+	return d
+}
+
+func userAttemptKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
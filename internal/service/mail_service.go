@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/mailer"
+	"simple-blog/internal/model"
+	"time"
+)
+
+const (
+	mailMaxAttempts  = 6
+	mailBatchSize    = 20
+	mailPollInterval = 10 * time.Second
+	mailLease        = 5 * time.Minute // 单次投递的租约时长，超时后视为投递中断可被重新领取
+	mailMaxBackoff   = time.Hour
+	mailRetention    = 7 * 24 * time.Hour // 已发送和最终失败的邮件保留时长，之后从队列中删除
+)
+
+// MailService 邮件队列：渲染模板写入 outbox，由后台任务投递并在失败时退避重试
+type MailService struct {
+	Mailer mailer.Mailer
+}
+
+var mailService = NewMailService()
+
+func NewMailService() *MailService {
+	return &MailService{
+		Mailer: mailer.New(config.GetMailConfig()),
+	}
+}
+
+// StartMailWorker 启动后台邮件投递任务
+func StartMailWorker() {
+	go func() {
+		ticker := time.NewTicker(mailPollInterval)
+		defer ticker.Stop()
+		for {
+			mailService.ProcessQueue()
+			mailService.PurgeOld()
+			<-ticker.C
+		}
+	}()
+}
+
+// Enqueue 渲染指定语言的模板并加入发送队列
+func (s *MailService) Enqueue(to, template, locale string, data interface{}) error {
+	msg, err := mailer.Render(template, locale, data)
+	if err != nil {
+		return err
+	}
+
+	item := model.MailOutbox{
+		To:            to,
+		Template:      template,
+		Subject:       msg.Subject,
+		Text:          msg.Text,
+		HTML:          msg.HTML,
+		Status:        "pending",
+		NextAttemptAt: time.Now(),
+	}
+	return database.DB.Create(&item).Error
+}
+
+// ProcessQueue 投递一批到期的邮件，返回处理的数量
+func (s *MailService) ProcessQueue() int {
+	var items []model.MailOutbox
+	now := time.Now()
+	err := database.DB.
+		Where("(status = ? OR status = ?) AND next_attempt_at <= ?", "pending", "sending", now).
+		Order("next_attempt_at asc").
+		Limit(mailBatchSize).
+		Find(&items).Error
+	if err != nil {
+		fmt.Printf("MailService: failed to load outbox: %v\n", err)
+		return 0
+	}
+
+	processed := 0
+	for i := range items {
+		// 条件更新领取任务，多实例部署时同一封邮件只会被一个实例投递
+		result := database.DB.Model(&model.MailOutbox{}).
+			Where("id = ? AND status = ? AND next_attempt_at = ?", items[i].ID, items[i].Status, items[i].NextAttemptAt).
+			Updates(map[string]interface{}{
+				"status":          "sending",
+				"next_attempt_at": now.Add(mailLease),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+		s.deliver(&items[i])
+		processed++
+	}
+	return processed
+}
+
+func (s *MailService) deliver(item *model.MailOutbox) {
+	err := s.Mailer.Send(&mailer.Message{
+		To:      []string{item.To},
+		Subject: item.Subject,
+		Text:    item.Text,
+		HTML:    item.HTML,
+	})
+
+	attempts := item.Attempts + 1
+	if err == nil {
+		now := time.Now()
+		// 正文中可能有一次性令牌的明文链接，发送后不再保留
+		database.DB.Model(&model.MailOutbox{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+			"status":     "sent",
+			"attempts":   attempts,
+			"sent_at":    &now,
+			"last_error": "",
+			"text":       "",
+			"html":       "",
+		})
+		return
+	}
+
+	fmt.Printf("MailService: failed to send mail %d to %s (attempt %d): %v\n", item.ID, item.To, attempts, err)
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": truncate(err.Error(), 1000),
+	}
+	if attempts >= mailMaxAttempts {
+		updates["status"] = "failed"
+	} else {
+		updates["status"] = "pending"
+		updates["next_attempt_at"] = time.Now().Add(mailBackoff(attempts))
+	}
+	database.DB.Model(&model.MailOutbox{}).Where("id = ?", item.ID).Updates(updates)
+}
+
+// mailBackoff 指数退避：1m, 2m, 4m ... 最长 1 小时
+func mailBackoff(attempts int) time.Duration {
+	backoff := time.Minute << (attempts - 1)
+	if backoff <= 0 || backoff > mailMaxBackoff {
+		return mailMaxBackoff
+	}
+	return backoff
+}
+
+// PurgeOld 删除超过保留时长的已发送和最终失败的邮件
+func (s *MailService) PurgeOld() {
+	err := database.DB.Unscoped().
+		Where("status IN ? AND updated_at < ?", []string{"sent", "failed"}, time.Now().Add(-mailRetention)).
+		Delete(&model.MailOutbox{}).Error
+	if err != nil {
+		fmt.Printf("MailService: failed to purge outbox: %v\n", err)
+	}
+}
+
+// ListFailed 分页获取最终发送失败的邮件
+func (s *MailService) ListFailed(page, pageSize int) ([]model.MailOutbox, int64, error) {
+	var items []model.MailOutbox
+	var total int64
+
+	db := database.DB.Model(&model.MailOutbox{}).Where("status = ?", "failed")
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := db.Order("updated_at desc").Offset(offset).Limit(pageSize).Find(&items).Error
+	return items, total, err
+}
+
+// Retry 将发送失败的邮件重新加入队列
+func (s *MailService) Retry(id uint) error {
+	result := database.DB.Model(&model.MailOutbox{}).
+		Where("id = ? AND status = ?", id, "failed").
+		Updates(map[string]interface{}{
+			"status":          "pending",
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("failed mail not found")
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MentionSourcePost    = "post"
+	MentionSourceComment = "comment"
+
+	MentionNotifyEveryone  = "everyone"
+	MentionNotifyFollowing = "following" // 只接收自己关注的用户的提及
+	MentionNotifyNone      = "none"
+
+	maxMentionsPerContent = 20 // 单篇内容最多记录和通知的用户数，防止滥发
+)
+
+// 与 usernamePattern 使用相同的字符集
+var mentionPattern = regexp.MustCompile(`@([\p{L}\p{N}_.\-]{2,30})`)
+
+// MentionService 解析文章和评论中的 @用户名，记录提及并通知被提及的用户
+type MentionService struct{}
+
+var mentionService = new(MentionService)
+
+// mentionMatch 内容中的一处 @用户名，Start 和 End 为字符位置
+type mentionMatch struct {
+	Name       string
+	Start, End int
+}
+
+// parseMentions 找出内容中的 @用户名。代码块和行内代码中的内容不算提及，
+// @ 前紧跟英文字母、数字等字符（如邮箱地址）时也不算；中文等不以空格分词的文字后可以直接提及
+func parseMentions(content string) []mentionMatch {
+	masked := maskCode(content)
+	var matches []mentionMatch
+	for _, loc := range mentionPattern.FindAllStringSubmatchIndex(masked, -1) {
+		if loc[0] > 0 {
+			prev, _ := utf8.DecodeLastRuneInString(masked[:loc[0]])
+			if isEmailRune(prev) || prev == '@' || prev == '/' {
+				continue
+			}
+		}
+		// 句末的标点不属于用户名
+		name := strings.TrimRight(masked[loc[2]:loc[3]], ".-")
+		if utf8.RuneCountInString(name) < 2 {
+			continue
+		}
+		start := utf8.RuneCountInString(content[:loc[0]])
+		matches = append(matches, mentionMatch{
+			Name:  name,
+			Start: start,
+			End:   start + 1 + utf8.RuneCountInString(name),
+		})
+	}
+	return matches
+}
+
+func isEmailRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-+", r))
+}
+
+// maskCode 将 Markdown 代码块和行内代码替换为等长的空格，保持其余内容的位置不变
+func maskCode(content string) string {
+	if !strings.Contains(content, "`") {
+		return content
+	}
+	lines := strings.SplitAfter(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			lines[i] = blank(line)
+			continue
+		}
+		if inFence {
+			lines[i] = blank(line)
+			continue
+		}
+		parts := strings.Split(line, "`")
+		// 奇数下标位于一对反引号之间，末尾未闭合的反引号不处理
+		for j := 1; j < len(parts)-1; j += 2 {
+			parts[j] = blank(parts[j])
+		}
+		lines[i] = strings.Join(parts, "`")
+	}
+	return strings.Join(lines, "")
+}
+
+// blank 按字节替换为空格，保持字节长度不变以便换算位置
+func blank(s string) string {
+	b := []byte(s)
+	for i := range b {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+	return string(b)
+}
+
+// resolveMentionedUsers 按用户名（不区分大小写）批量查找用户，返回以小写用户名为键的用户。
+// 只匹配当前用户名，历史用户名可能已被他人使用
+func resolveMentionedUsers(db *gorm.DB, names []string) map[string]model.User {
+	users := make(map[string]model.User)
+	if len(names) == 0 {
+		return users
+	}
+	lowered := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := strings.ToLower(name)
+		if !seen[key] {
+			seen[key] = true
+			lowered = append(lowered, key)
+		}
+	}
+
+	var list []model.User
+	db.Model(&model.User{}).Select("id", "username", "mention_notify").
+		Where("LOWER(username) IN ?", lowered).
+		Find(&list)
+	for _, user := range list {
+		users[strings.ToLower(user.Username)] = user
+	}
+	return users
+}
+
+// mentionSpans 批量计算多段内容中的提及位置，无法解析到用户的 @用户名 不返回
+func mentionSpans(contents []string) [][]model.MentionSpan {
+	parsed := make([][]mentionMatch, len(contents))
+	var names []string
+	for i, content := range contents {
+		parsed[i] = parseMentions(content)
+		for _, m := range parsed[i] {
+			names = append(names, m.Name)
+		}
+	}
+	users := resolveMentionedUsers(database.DB, names)
+
+	spans := make([][]model.MentionSpan, len(contents))
+	for i, matches := range parsed {
+		for _, m := range matches {
+			if user, ok := users[strings.ToLower(m.Name)]; ok {
+				spans[i] = append(spans[i], model.MentionSpan{UserID: user.ID, Username: user.Username, Start: m.Start, End: m.End})
+			}
+		}
+	}
+	return spans
+}
+
+// attachPostMentions 为文章填充提及位置
+func attachPostMentions(posts []model.Post) {
+	contents := make([]string, len(posts))
+	for i := range posts {
+		contents[i] = posts[i].Content
+	}
+	for i, spans := range mentionSpans(contents) {
+		posts[i].Mentions = spans
+	}
+}
+
+// attachCommentMentions 为评论及其回复填充提及位置
+func attachCommentMentions(comments []model.Comment) {
+	var contents []string
+	for i := range comments {
+		contents = append(contents, comments[i].Content)
+		for j := range comments[i].Replies {
+			contents = append(contents, comments[i].Replies[j].Content)
+		}
+	}
+	spans := mentionSpans(contents)
+	k := 0
+	for i := range comments {
+		comments[i].Mentions = spans[k]
+		k++
+		for j := range comments[i].Replies {
+			comments[i].Replies[j].Mentions = spans[k]
+			k++
+		}
+	}
+}
+
+// sync 根据内容更新提及记录，返回新增的被提及用户，供提交后发送通知。
+// active 为 false（如草稿）时清除该内容的所有提及
+func (s *MentionService) sync(tx *gorm.DB, sourceType string, sourceID, postID, authorID uint, content string, active bool) ([]model.User, error) {
+	var wanted []model.User
+	if active {
+		var names []string
+		for _, m := range parseMentions(content) {
+			names = append(names, m.Name)
+		}
+		users := resolveMentionedUsers(tx, names)
+		seen := make(map[uint]bool)
+		for _, name := range names {
+			user, ok := users[strings.ToLower(name)]
+			if !ok || user.ID == authorID || seen[user.ID] {
+				continue
+			}
+			seen[user.ID] = true
+			wanted = append(wanted, user)
+			if len(wanted) >= maxMentionsPerContent {
+				break
+			}
+		}
+	}
+
+	var existing []model.Mention
+	if err := tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	keep := make(map[uint]bool, len(wanted))
+	for _, user := range wanted {
+		keep[user.ID] = true
+	}
+	had := make(map[uint]bool, len(existing))
+	for _, mention := range existing {
+		had[mention.UserID] = true
+		if !keep[mention.UserID] {
+			if err := tx.Delete(&mention).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var added []model.User
+	for _, user := range wanted {
+		if had[user.ID] {
+			continue
+		}
+		mention := model.Mention{UserID: user.ID, SourceType: sourceType, SourceID: sourceID, PostID: postID, AuthorID: authorID}
+		if err := tx.Create(&mention).Error; err != nil {
+			return nil, err
+		}
+		added = append(added, user)
+	}
+	return added, nil
+}
+
+// notify 通知新被提及的用户。skip 中的用户已经因回复等收到通知，不再重复发送；
+// 无权查看文章的用户和关闭了提及通知的用户不会收到通知，屏蔽了作者的用户由 CreateNotification 过滤
+func (s *MentionService) notify(users []model.User, authorID, postAuthorID, postID, commentID uint, content string, skip map[uint]bool) {
+	for _, user := range users {
+		if skip[user.ID] || !canViewAuthor(postAuthorID, Actor{UserID: user.ID}) {
+			continue
+		}
+		switch user.MentionNotify {
+		case MentionNotifyNone:
+			continue
+		case MentionNotifyFollowing:
+			if !userService.IsFollowing(user.ID, authorID) {
+				continue
+			}
+		}
+		notificationService.CreateNotification(&model.Notification{
+			UserID:     user.ID,
+			Type:       "mention",
+			Content:    content,
+			FromUserID: authorID,
+			PostID:     postID,
+			CommentID:  commentID,
+		})
+	}
+}
+
+// removeSource 删除文章或评论时清除其中的提及
+func (s *MentionService) removeSource(tx *gorm.DB, sourceType string, sourceID uint) error {
+	return tx.Where("source_type = ? AND source_id = ?", sourceType, sourceID).Delete(&model.Mention{}).Error
+}
+
+// ListMentions 列出提及用户的内容，最新的在前。屏蔽的用户和已无权查看的文章不会出现
+func (s *MentionService) ListMentions(userID uint, page, pageSize int) ([]model.Mention, int64, error) {
+	var mentions []model.Mention
+	var total int64
+	db := database.DB.Model(&model.Mention{}).
+		Joins("JOIN posts ON posts.id = mentions.post_id AND posts.deleted_at IS NULL").
+		Where("mentions.user_id = ?", userID)
+	db = excludeHiddenUsers(db, userID, "mentions.author_id", BlockKindBlock)
+	db = visiblePosts(db, Actor{UserID: userID})
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	err := db.Preload("Author").
+		Order("mentions.created_at desc").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&mentions).Error
+	return mentions, total, err
+}
+
+// SetMentionNotify 设置接收哪些人的提及通知
+func (s *MentionService) SetMentionNotify(userID uint, value string) error {
+	switch value {
+	case MentionNotifyEveryone, MentionNotifyFollowing, MentionNotifyNone:
+	default:
+		return errors.New("invalid mention notify setting")
+	}
+	return database.DB.Model(&model.User{}).Where("id = ?", userID).Update("mention_notify", value).Error
+}
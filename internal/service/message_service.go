@@ -0,0 +1,347 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	MessageFromEveryone  = "everyone"
+	MessageFromFollowing = "following" // 只接收自己关注的用户的私信
+
+	maxMessageLength    = 2000
+	defaultMessageLimit = 30
+	maxMessageLimit     = 100
+)
+
+// MessageService 用户之间一对一的私信
+type MessageService struct{}
+
+var messageService = new(MessageService)
+
+// Send 向用户发送私信，双方之间还没有会话时自动创建。
+// 任一方屏蔽了对方，或对方只接收关注的人的私信时不能发送
+func (s *MessageService) Send(senderID, recipientID uint, content string) (*model.Message, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("content is required")
+	}
+	if utf8.RuneCountInString(content) > maxMessageLength {
+		return nil, errors.New("message too long")
+	}
+	if senderID == recipientID {
+		return nil, errors.New("cannot message yourself")
+	}
+
+	var recipient model.User
+	if err := database.DB.Select("id", "message_from").First(&recipient, recipientID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if blockService.HasBlocked(recipientID, senderID) || blockService.HasBlocked(senderID, recipientID) {
+		return nil, errors.New("blocked")
+	}
+	if recipient.MessageFrom == MessageFromFollowing && !userService.IsFollowing(recipientID, senderID) {
+		return nil, errors.New("recipient does not accept messages from you")
+	}
+
+	message := model.Message{SenderID: senderID, Content: content}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		conversation, err := s.findOrCreate(tx, senderID, recipientID)
+		if err != nil {
+			return err
+		}
+		message.ConversationID = conversation.ID
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(conversation).Updates(map[string]interface{}{
+			"last_message_id": message.ID,
+			"last_message_at": message.CreatedAt,
+		}).Error; err != nil {
+			return err
+		}
+		// 自己发送的私信视为已读
+		return tx.Model(&model.ConversationMember{}).
+			Where("conversation_id = ? AND user_id = ?", conversation.ID, senderID).
+			Update("last_read_id", message.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// Reply 在已有会话中发送私信
+func (s *MessageService) Reply(userID, conversationID uint, content string) (*model.Message, error) {
+	conversation, err := s.find(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Send(userID, peerOf(conversation, userID), content)
+}
+
+// ListConversations 列出用户的会话，按最近一条私信的时间倒序，返回下一页的游标。
+// 与已屏蔽用户的会话不显示
+func (s *MessageService) ListConversations(userID uint, cursor string, limit int) ([]model.Conversation, string, error) {
+	limit = messageLimit(limit)
+	after, err := ParseFeedCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	db := database.DB.Model(&model.Conversation{}).
+		Joins("JOIN conversation_members ON conversation_members.conversation_id = conversations.id AND conversation_members.user_id = ?", userID).
+		Where("conversations.last_message_id > 0")
+	// 自己不会出现在屏蔽列表中，两个成员字段都排除即排除了对方
+	db = excludeHiddenUsers(db, userID, "conversations.user_a_id", BlockKindBlock)
+	db = excludeHiddenUsers(db, userID, "conversations.user_b_id", BlockKindBlock)
+	if after != nil {
+		db = db.Where("(conversations.last_message_at < ? OR (conversations.last_message_at = ? AND conversations.id < ?))",
+			after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	var conversations []model.Conversation
+	if err := db.Order("conversations.last_message_at DESC, conversations.id DESC").Limit(limit + 1).Find(&conversations).Error; err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(conversations) > limit {
+		conversations = conversations[:limit]
+		last := conversations[len(conversations)-1]
+		next = FeedCursor{CreatedAt: last.LastMessageAt, ID: last.ID}.Encode()
+	}
+	s.decorate(userID, conversations)
+	return conversations, next, nil
+}
+
+// GetConversation 获取单个会话的概况
+func (s *MessageService) GetConversation(userID, conversationID uint) (*model.Conversation, error) {
+	conversation, err := s.find(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	list := []model.Conversation{*conversation}
+	s.decorate(userID, list)
+	return &list[0], nil
+}
+
+// GetMessages 获取会话中的私信，从最新的开始倒序翻页
+func (s *MessageService) GetMessages(userID, conversationID uint, cursor string, limit int) ([]model.Message, string, error) {
+	conversation, err := s.find(userID, conversationID)
+	if err != nil {
+		return nil, "", err
+	}
+	limit = messageLimit(limit)
+	after, err := ParseFeedCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	db := database.DB.Where("conversation_id = ?", conversation.ID)
+	if after != nil {
+		db = db.Where("(created_at < ? OR (created_at = ? AND id < ?))", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+	var messages []model.Message
+	if err := db.Order("created_at DESC, id DESC").Limit(limit + 1).Find(&messages).Error; err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if len(messages) > limit {
+		messages = messages[:limit]
+		last := messages[len(messages)-1]
+		next = FeedCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	// 接收方的已读位置之前的私信均为已读
+	readUpTo := s.lastReadIDs(conversation.ID)
+	for i := range messages {
+		recipient := peerOf(conversation, messages[i].SenderID)
+		messages[i].Read = messages[i].ID <= readUpTo[recipient]
+	}
+	return messages, next, nil
+}
+
+// MarkRead 将会话标记为已读到最新一条私信
+func (s *MessageService) MarkRead(userID, conversationID uint) error {
+	conversation, err := s.find(userID, conversationID)
+	if err != nil {
+		return err
+	}
+	return database.DB.Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND last_read_id < ?", conversation.ID, userID, conversation.LastMessageID).
+		Update("last_read_id", conversation.LastMessageID).Error
+}
+
+// SetMuted 静音或取消静音会话，静音的会话不计入未读总数
+func (s *MessageService) SetMuted(userID, conversationID uint, muted bool) error {
+	conversation, err := s.find(userID, conversationID)
+	if err != nil {
+		return err
+	}
+	return database.DB.Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversation.ID, userID).
+		Update("muted", muted).Error
+}
+
+// UnreadCount 返回未静音会话中的未读私信总数，与通知一致不统计已屏蔽用户的私信
+func (s *MessageService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	db := excludeHiddenUsers(database.DB, userID, "messages.sender_id", BlockKindBlock)
+	err := db.Model(&model.Message{}).
+		Joins("JOIN conversation_members ON conversation_members.conversation_id = messages.conversation_id").
+		Where("conversation_members.user_id = ? AND conversation_members.muted = ?", userID, false).
+		Where("messages.id > conversation_members.last_read_id AND messages.sender_id <> ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// SetMessageFrom 设置允许哪些人发私信
+func (s *MessageService) SetMessageFrom(userID uint, value string) error {
+	if value != MessageFromEveryone && value != MessageFromFollowing {
+		return errors.New("invalid message setting")
+	}
+	return database.DB.Model(&model.User{}).Where("id = ?", userID).Update("message_from", value).Error
+}
+
+// decorate 填充会话的对方用户、最近一条私信、未读数和静音状态
+func (s *MessageService) decorate(userID uint, conversations []model.Conversation) {
+	if len(conversations) == 0 {
+		return
+	}
+	ids := make([]uint, len(conversations))
+	peerIDs := make([]uint, len(conversations))
+	messageIDs := make([]uint, len(conversations))
+	for i := range conversations {
+		ids[i] = conversations[i].ID
+		peerIDs[i] = peerOf(&conversations[i], userID)
+		messageIDs[i] = conversations[i].LastMessageID
+	}
+
+	var peers []model.User
+	database.DB.Where("id IN ?", peerIDs).Find(&peers)
+	peerByID := make(map[uint]*model.User, len(peers))
+	for i := range peers {
+		peerByID[peers[i].ID] = &peers[i]
+	}
+
+	var messages []model.Message
+	database.DB.Where("id IN ?", messageIDs).Find(&messages)
+	messageByID := make(map[uint]*model.Message, len(messages))
+	for i := range messages {
+		messageByID[messages[i].ID] = &messages[i]
+	}
+
+	var members []model.ConversationMember
+	database.DB.Where("conversation_id IN ?", ids).Find(&members)
+	mine := make(map[uint]model.ConversationMember)
+	theirs := make(map[uint]model.ConversationMember)
+	for _, member := range members {
+		if member.UserID == userID {
+			mine[member.ConversationID] = member
+		} else {
+			theirs[member.ConversationID] = member
+		}
+	}
+
+	var unread []struct {
+		ConversationID uint
+		Count          int64
+	}
+	excludeHiddenUsers(database.DB, userID, "messages.sender_id", BlockKindBlock).Model(&model.Message{}).
+		Select("messages.conversation_id, COUNT(*) as count").
+		Joins("JOIN conversation_members ON conversation_members.conversation_id = messages.conversation_id AND conversation_members.user_id = ?", userID).
+		Where("messages.conversation_id IN ? AND messages.id > conversation_members.last_read_id AND messages.sender_id <> ?", ids, userID).
+		Group("messages.conversation_id").
+		Scan(&unread)
+	unreadByID := make(map[uint]int64, len(unread))
+	for _, row := range unread {
+		unreadByID[row.ConversationID] = row.Count
+	}
+
+	for i := range conversations {
+		c := &conversations[i]
+		c.Peer = peerByID[peerOf(c, userID)]
+		c.LastMessage = messageByID[c.LastMessageID]
+		c.UnreadCount = unreadByID[c.ID]
+		c.Muted = mine[c.ID].Muted
+		c.PeerLastReadID = theirs[c.ID].LastReadID
+		if c.LastMessage != nil {
+			if c.LastMessage.SenderID == userID {
+				c.LastMessage.Read = c.LastMessage.ID <= c.PeerLastReadID
+			} else {
+				c.LastMessage.Read = c.LastMessage.ID <= mine[c.ID].LastReadID
+			}
+		}
+	}
+}
+
+// lastReadIDs 返回会话中每个成员已读到的私信ID
+func (s *MessageService) lastReadIDs(conversationID uint) map[uint]uint {
+	var members []model.ConversationMember
+	database.DB.Where("conversation_id = ?", conversationID).Find(&members)
+	result := make(map[uint]uint, len(members))
+	for _, member := range members {
+		result[member.UserID] = member.LastReadID
+	}
+	return result
+}
+
+// findOrCreate 查找两个用户之间的会话，不存在时创建
+func (s *MessageService) findOrCreate(tx *gorm.DB, userID, peerID uint) (*model.Conversation, error) {
+	a, b := userID, peerID
+	if a > b {
+		a, b = b, a
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.Conversation{UserAID: a, UserBID: b, LastMessageAt: time.Now()}).Error; err != nil {
+		return nil, err
+	}
+	var conversation model.Conversation
+	if err := tx.Where("user_a_id = ? AND user_b_id = ?", a, b).First(&conversation).Error; err != nil {
+		return nil, err
+	}
+	members := []model.ConversationMember{
+		{ConversationID: conversation.ID, UserID: a},
+		{ConversationID: conversation.ID, UserID: b},
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&members).Error; err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// find 查找用户参与的会话
+func (s *MessageService) find(userID, conversationID uint) (*model.Conversation, error) {
+	var conversation model.Conversation
+	if err := database.DB.Where("id = ? AND (user_a_id = ? OR user_b_id = ?)", conversationID, userID, userID).
+		First(&conversation).Error; err != nil {
+		return nil, errors.New("conversation not found")
+	}
+	return &conversation, nil
+}
+
+// peerOf 返回会话中 userID 之外的另一方
+func peerOf(conversation *model.Conversation, userID uint) uint {
+	if conversation.UserAID == userID {
+		return conversation.UserBID
+	}
+	return conversation.UserAID
+}
+
+func messageLimit(limit int) int {
+	if limit <= 0 {
+		return defaultMessageLimit
+	}
+	if limit > maxMessageLimit {
+		return maxMessageLimit
+	}
+	return limit
+}
@@ -7,13 +7,20 @@ import (
 
 type NotificationService struct{}
 
+var notificationService = new(NotificationService)
+
+// CreateNotification 创建通知，接收者屏蔽了触发者时不发送
 func (s *NotificationService) CreateNotification(notif *model.Notification) error {
+	if notif.FromUserID != 0 && blockService.HasBlocked(notif.UserID, notif.FromUserID) {
+		return nil
+	}
 	return database.DB.Create(notif).Error
 }
 
 func (s *NotificationService) GetUserNotifications(userID uint) ([]model.Notification, error) {
 	var notifs []model.Notification
-	err := database.DB.Preload("FromUser").
+	db := excludeHiddenUsers(database.DB, userID, "from_user_id", BlockKindBlock)
+	err := db.Preload("FromUser").
 		Where("user_id = ?", userID).
 		Order("created_at desc").
 		Find(&notifs).Error
@@ -26,9 +33,11 @@ func (s *NotificationService) MarkAsRead(userID uint, notifID uint) error {
 		Update("is_read", true).Error
 }
 
+// GetUnreadCount 未读通知数，与通知列表一致不统计已屏蔽用户的通知
 func (s *NotificationService) GetUnreadCount(userID uint) (int64, error) {
 	var count int64
-	err := database.DB.Model(&model.Notification{}).
+	db := excludeHiddenUsers(database.DB, userID, "from_user_id", BlockKindBlock)
+	err := db.Model(&model.Notification{}).
 		Where("user_id = ? AND is_read = ?", userID, false).
 		Count(&count).Error
 	return count, err
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"regexp"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"simple-blog/internal/oauth"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// OAuthStateTTL 授权请求的有效期，也用作保存 state 的 Cookie 的有效期
+const OAuthStateTTL = 10 * time.Minute
+
+// OAuthService 第三方登录与账号绑定
+type OAuthService struct {
+	providers map[string]*oauth.Provider
+}
+
+func NewOAuthService() *OAuthService {
+	providers := make(map[string]*oauth.Provider)
+	for _, cfg := range config.GetOAuthProviders() {
+		providers[cfg.Name] = oauth.NewProvider(cfg)
+	}
+	return &OAuthService{providers: providers}
+}
+
+// ListProviders 返回已配置的提供方名称
+func (s *OAuthService) ListProviders() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartLogin 生成授权地址和 state。linkUserID 非 0 时表示为已登录用户绑定第三方账号。
+// 调用方需将 state 保存在发起授权的浏览器中（HttpOnly Cookie），回调时一并提交校验
+func (s *OAuthService) StartLogin(ctx context.Context, providerName string, linkUserID uint) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", errors.New("unknown provider")
+	}
+
+	state, err := oauth.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oauth.RandomString(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oauth.NewPKCEVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+
+	// 顺便清理过期的授权请求
+	database.DB.Where("expires_at < ?", time.Now()).Delete(&model.OAuthState{})
+
+	record := model.OAuthState{
+		StateHash:    hashToken(state),
+		Provider:     providerName,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(OAuthStateTTL),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// CompleteLogin 处理回调：校验 state，换取第三方身份，然后登录、绑定或自动创建账号。
+// browserState 为发起授权时保存在浏览器中的 state，callerID 为当前登录用户（未登录为 0）
+func (s *OAuthService) CompleteLogin(ctx context.Context, providerName, code, state, browserState string, callerID uint, client ClientInfo) (*LoginResult, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errors.New("unknown provider")
+	}
+	// state 必须由同一个浏览器发起，防止攻击者把自己发起的授权请求交给他人完成
+	if browserState == "" || subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return nil, errors.New("invalid or expired state")
+	}
+
+	var record model.OAuthState
+	err := database.DB.Where("state_hash = ? AND provider = ?", hashToken(state), providerName).First(&record).Error
+	if err != nil || time.Now().After(record.ExpiresAt) {
+		return nil, errors.New("invalid or expired state")
+	}
+	// state 只能使用一次
+	result := database.DB.Delete(&model.OAuthState{}, record.ID)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, errors.New("invalid or expired state")
+	}
+	// 绑定只能由发起绑定的用户本人完成
+	if record.LinkUserID != 0 && record.LinkUserID != callerID {
+		return nil, errors.New("please log in as the user who started linking")
+	}
+
+	identity, err := provider.Exchange(ctx, code, record.CodeVerifier, record.Nonce)
+	if err != nil {
+		fmt.Printf("OAuth: %s exchange failed: %v\n", providerName, err)
+		return nil, errors.New("failed to verify identity with provider")
+	}
+
+	if record.LinkUserID != 0 {
+		if err := s.linkIdentity(record.LinkUserID, providerName, identity); err != nil {
+			return nil, err
+		}
+		return &LoginResult{UserID: record.LinkUserID, Linked: true}, nil
+	}
+
+	var linked model.UserIdentity
+	err = database.DB.Where("provider = ? AND subject = ?", providerName, identity.Subject).First(&linked).Error
+	if err == nil {
+		var user model.User
+		if err := database.DB.First(&user, linked.UserID).Error; err != nil {
+			return nil, errors.New("linked user not found")
+		}
+		return userService.beginSession(&user, client)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	user, err := s.provisionUser(providerName, identity)
+	if err != nil {
+		return nil, err
+	}
+	return userService.beginSession(user, client)
+}
+
+func (s *OAuthService) linkIdentity(userID uint, providerName string, identity *oauth.Identity) error {
+	var existing model.UserIdentity
+	err := database.DB.Where("provider = ? AND subject = ?", providerName, identity.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID == userID {
+			return nil
+		}
+		return errors.New("this account is already linked to another user")
+	}
+
+	return database.DB.Create(&model.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Username: identity.Username,
+		Email:    identity.Email,
+	}).Error
+}
+
+// provisionUser 首次使用第三方账号登录时自动创建本站账号。
+// 邮箱已被本站账号使用时不自动关联，避免通过第三方邮箱接管他人账号
+func (s *OAuthService) provisionUser(providerName string, identity *oauth.Identity) (*model.User, error) {
+	// 第三方登录无法携带邀请码，只有开放注册时才自动创建账号
+	switch inviteService.RegistrationMode() {
+	case RegistrationClosed:
+		return nil, errors.New("registration is closed")
+	case RegistrationInvite:
+		return nil, errors.New("registration requires an invite code, please register first and link this account from your settings")
+	}
+
+	email := ""
+	if identity.Email != "" {
+		var count int64
+		database.DB.Model(&model.User{}).Where("email = ?", identity.Email).Count(&count)
+		if count > 0 {
+			return nil, errors.New("email already registered, please log in and link this account from your settings")
+		}
+		email = identity.Email
+	}
+
+	// 本地密码随机生成，用户可通过找回密码设置
+	random, err := randomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(random), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	var user model.User
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		username, err := availableUsername(tx, providerName, identity)
+		if err != nil {
+			return err
+		}
+		user = model.User{
+			Username:      username,
+			Password:      string(hashedPassword),
+			Email:         email,
+			EmailVerified: email != "" && identity.EmailVerified,
+			Avatar:        identity.Avatar,
+			BlogName:      identity.Name,
+		}
+		// 没有邮箱时不能写入空字符串，否则会与唯一索引冲突
+		if email == "" {
+			if err := tx.Omit("Email").Create(&user).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.UserIdentity{
+			UserID:   user.ID,
+			Provider: providerName,
+			Subject:  identity.Subject,
+			Username: identity.Username,
+			Email:    identity.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+var usernameSanitizer = regexp.MustCompile(`[^A-Za-z0-9_\-]+`)
+
+// availableUsername 根据第三方用户名生成本站可用的用户名，重名时追加数字
+func availableUsername(tx *gorm.DB, providerName string, identity *oauth.Identity) (string, error) {
+	base := identity.Username
+	if base == "" && identity.Email != "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	base = usernameSanitizer.ReplaceAllString(base, "")
+	if base == "" {
+		base = providerName + "_user"
+	} else if len(base) < 2 {
+		base = providerName + "_" + base
+	}
+	if len(base) > 30 {
+		base = base[:30]
+	}
+
+	for i := 0; i < 100; i++ {
+		candidate := base
+		if i > 0 {
+			candidate = fmt.Sprintf("%s%d", base, i+1)
+		}
+		if usernameAvailable(tx, candidate, 0) == nil {
+			return candidate, nil
+		}
+	}
+	return "", errors.New("unable to allocate a username")
+}
+
+func (s *OAuthService) ListIdentities(userID uint) ([]model.UserIdentity, error) {
+	var identities []model.UserIdentity
+	err := database.DB.Where("user_id = ?", userID).Order("created_at asc").Find(&identities).Error
+	return identities, err
+}
+
+// UnlinkIdentity 解除第三方账号绑定。
+// 为避免用户失去所有登录方式，需要还有其他绑定或已验证邮箱（可通过找回密码登录）
+func (s *OAuthService) UnlinkIdentity(userID, identityID uint) error {
+	var identity model.UserIdentity
+	if err := database.DB.Where("id = ? AND user_id = ?", identityID, userID).First(&identity).Error; err != nil {
+		return errors.New("identity not found")
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	var others int64
+	database.DB.Model(&model.UserIdentity{}).Where("user_id = ? AND id <> ?", userID, identityID).Count(&others)
+	if others == 0 && !user.EmailVerified {
+		return errors.New("verify your email before unlinking your last external account")
+	}
+
+	return database.DB.Unscoped().Delete(&identity).Error
+}
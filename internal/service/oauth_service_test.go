@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"simple-blog/internal/oauth"
+	"simple-blog/internal/oauth/oauthtest"
+	"testing"
+	"time"
+)
+
+// newMockOAuthService 启动本地 OIDC 提供方，返回只配置了该提供方（名称为 mock）的 OAuthService
+func newMockOAuthService(t *testing.T) (*oauthtest.Server, *OAuthService) {
+	t.Helper()
+	server, err := oauthtest.NewServer("blog", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Close)
+	provider := oauth.NewProvider(config.OAuthProviderConfig{
+		Name:         "mock",
+		ClientID:     "blog",
+		ClientSecret: "secret",
+		Issuer:       server.URL,
+		RedirectURL:  "http://localhost:5173/oauth/callback/mock",
+	})
+	return server, &OAuthService{providers: map[string]*oauth.Provider{"mock": provider}}
+}
+
+func newIdPUser() oauthtest.User {
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	return oauthtest.User{
+		Subject:       "sub-" + suffix,
+		Username:      "idp_" + suffix,
+		Email:         "idp_" + suffix + "@example.com",
+		EmailVerified: true,
+	}
+}
+
+func TestOAuthLoginProvisionsUser(t *testing.T) {
+	setupTestDB(t)
+	if inviteService.RegistrationMode() != RegistrationOpen {
+		t.Skip("registration is not open in the test database")
+	}
+	server, s := newMockOAuthService(t)
+	idpUser := newIdPUser()
+	ctx := context.Background()
+
+	authURL, state, err := s.StartLogin(ctx, "mock", 0)
+	if err != nil {
+		t.Fatalf("start login: %v", err)
+	}
+	code, returnedState, err := server.Authorize(authURL, idpUser)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+
+	result, err := s.CompleteLogin(ctx, "mock", code, returnedState, state, 0, ClientInfo{})
+	if err != nil {
+		t.Fatalf("complete login: %v", err)
+	}
+	if result.Token == "" || result.UserID == 0 {
+		t.Fatalf("expected a session for the provisioned user, got %+v", result)
+	}
+	var identity model.UserIdentity
+	if err := database.DB.Where("provider = ? AND subject = ?", "mock", idpUser.Subject).First(&identity).Error; err != nil {
+		t.Fatalf("identity not stored: %v", err)
+	}
+	if identity.UserID != result.UserID {
+		t.Fatalf("identity linked to user %d, want %d", identity.UserID, result.UserID)
+	}
+
+	// 同一个 state 不能再次使用
+	if _, err := s.CompleteLogin(ctx, "mock", code, returnedState, state, 0, ClientInfo{}); err == nil {
+		t.Fatal("expected reused state to be rejected")
+	}
+}
+
+func TestOAuthCallbackRequiresBrowserState(t *testing.T) {
+	setupTestDB(t)
+	server, s := newMockOAuthService(t)
+	ctx := context.Background()
+
+	// 攻击者发起授权，把授权地址交给受害者完成；受害者的浏览器中没有攻击者的 state
+	authURL, attackerState, err := s.StartLogin(ctx, "mock", 0)
+	if err != nil {
+		t.Fatalf("start login: %v", err)
+	}
+	code, returnedState, err := server.Authorize(authURL, newIdPUser())
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+
+	if _, err := s.CompleteLogin(ctx, "mock", code, returnedState, "", 0, ClientInfo{}); err == nil {
+		t.Fatal("expected callback without browser state to be rejected")
+	}
+	_, victimState, err := s.StartLogin(ctx, "mock", 0)
+	if err != nil {
+		t.Fatalf("start login: %v", err)
+	}
+	if _, err := s.CompleteLogin(ctx, "mock", code, returnedState, victimState, 0, ClientInfo{}); err == nil {
+		t.Fatal("expected callback with another browser's state to be rejected")
+	}
+	if returnedState != attackerState {
+		t.Fatal("provider returned a different state")
+	}
+}
+
+func TestOAuthLinkRequiresInitiatingUser(t *testing.T) {
+	setupTestDB(t)
+	server, s := newMockOAuthService(t)
+	owner := createTestUser(t, "password")
+	other := createTestUser(t, "password")
+	idpUser := newIdPUser()
+	ctx := context.Background()
+
+	authURL, state, err := s.StartLogin(ctx, "mock", owner.ID)
+	if err != nil {
+		t.Fatalf("start link: %v", err)
+	}
+	code, returnedState, err := server.Authorize(authURL, idpUser)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	if _, err := s.CompleteLogin(ctx, "mock", code, returnedState, state, other.ID, ClientInfo{}); err == nil {
+		t.Fatal("expected link completed by another user to be rejected")
+	}
+	var count int64
+	database.DB.Model(&model.UserIdentity{}).Where("provider = ? AND subject = ?", "mock", idpUser.Subject).Count(&count)
+	if count != 0 {
+		t.Fatal("identity was linked by another user")
+	}
+
+	authURL, state, err = s.StartLogin(ctx, "mock", owner.ID)
+	if err != nil {
+		t.Fatalf("start link: %v", err)
+	}
+	code, returnedState, err = server.Authorize(authURL, idpUser)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	result, err := s.CompleteLogin(ctx, "mock", code, returnedState, state, owner.ID, ClientInfo{})
+	if err != nil {
+		t.Fatalf("complete link: %v", err)
+	}
+	if !result.Linked || result.UserID != owner.ID {
+		t.Fatalf("unexpected link result: %+v", result)
+	}
+}
@@ -2,46 +2,81 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"simple-blog/internal/database"
 	"simple-blog/internal/model"
+	"simple-blog/internal/slug"
+	"strconv"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 type PostService struct{}
 
+var auditService = new(AuditService)
+
 func (s *PostService) CreatePost(post *model.Post) error {
-	// 处理标签
-	var tags []model.Tag
-	for _, tagName := range post.TagNames {
-		var tag model.Tag
-		// 查找或创建标签
-		if err := database.DB.FirstOrCreate(&tag, model.Tag{Name: tagName}).Error; err != nil {
+	var mentioned []model.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 处理标签：同义词和大小写、全半角不同的写法归入同一个标签
+		tags, err := resolveTags(tx, post.TagNames)
+		if err != nil {
 			return err
 		}
-		tags = append(tags, tag)
-	}
-	post.Tags = tags
+		post.Tags = tags
 
-	return database.DB.Create(post).Error
+		if err := assignSlug(tx, post); err != nil {
+			return err
+		}
+		post.CategoryID, err = validateCategory(tx, post.CategoryID)
+		if err != nil {
+			return err
+		}
+		post.Category = nil
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		mentioned, err = mentionService.sync(tx, MentionSourcePost, post.ID, post.ID, post.UserID, post.Content, isPublished(post))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	mentionService.notify(mentioned, post.UserID, post.UserID, post.ID, 0, post.Title, nil)
+	return nil
 }
 
-func (s *PostService) GetPostDetail(id string) (*model.Post, error) {
+// GetPostDetail 获取文章详情，viewer 无权查看私密账号的文章时返回 post not found
+func (s *PostService) GetPostDetail(id string, viewer Actor) (*model.Post, error) {
 	var post model.Post
 	if err := database.DB.Model(&model.Post{}).
 		Select("posts.*, "+
 			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, "+
 			"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.post_id = posts.id) as favorite_count, "+
-			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count").
-		Preload("User").Preload("Tags").First(&post, id).Error; err != nil {
+			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count, "+
+			repostCountColumn).
+		Preload("User").Preload("Tags").Preload("Category").First(&post, id).Error; err != nil {
 		return nil, err
 	}
+	if !canViewAuthor(post.UserID, viewer) {
+		return nil, errors.New("post not found")
+	}
 	// 增加阅读量
 	database.DB.Model(&post).UpdateColumn("view_count", gorm.Expr("view_count + ?", 1))
+	post.Series = seriesService.PostSeriesInfo(&post)
+	post.Reactions = reactionService.Summaries(ReactionTargetPost, []uint{post.ID}, viewer.UserID)[post.ID]
+	post.Mentions = mentionSpans([]string{post.Content})[0]
+	posts := []model.Post{post}
+	attachReposts(posts, viewer)
+	post = posts[0]
 	return &post, nil
 }
 
-func (s *PostService) GetPostList(page, pageSize int, tagName string, userID uint, status string, orderBy string, keyword string) ([]model.Post, int64, error) {
+// GetPostList 分页查询文章，viewer 为当前用户（未登录时为零值）。
+// viewer 屏蔽的用户的文章，以及无权查看的私密账号的文章不会出现
+func (s *PostService) GetPostList(page, pageSize int, tagName string, categoryID uint, userID uint, status string, orderBy string, keyword string, viewer Actor) ([]model.Post, int64, error) {
 	var posts []model.Post
 	var total int64
 
@@ -58,9 +93,19 @@ func (s *PostService) GetPostList(page, pageSize int, tagName string, userID uin
 
 	// 如果有标签筛选
 	if tagName != "" {
+		_, key := normalizeTagName(tagName)
 		db = db.Joins("JOIN post_tags ON post_tags.post_id = posts.id").
 			Joins("JOIN tags ON tags.id = post_tags.tag_id").
-			Where("tags.name = ?", tagName)
+			Where("tags.normalized_name = ? OR tags.id IN (SELECT tag_id FROM tag_synonyms WHERE name = ? AND deleted_at IS NULL)", key, key)
+	}
+
+	// 分类筛选包含所有子分类
+	if categoryID > 0 {
+		ids, err := categoryService.DescendantIDs(categoryID)
+		if err != nil {
+			return nil, 0, err
+		}
+		db = db.Where("posts.category_id IN ?", ids)
 	}
 
 	if userID > 0 {
@@ -71,6 +116,15 @@ func (s *PostService) GetPostList(page, pageSize int, tagName string, userID uin
 		db = db.Where("posts.status = ?", status)
 	}
 
+	// 直接转发只出现在转发者的主页
+	if userID == 0 {
+		db = withoutPlainReposts(db)
+	}
+	db = withoutOrphanReposts(db, viewer)
+
+	db = excludeHiddenUsers(db, viewer.UserID, "posts.user_id", BlockKindBlock)
+	db = visiblePosts(db, viewer)
+
 	if err := db.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
@@ -79,7 +133,8 @@ func (s *PostService) GetPostList(page, pageSize int, tagName string, userID uin
 	db = db.Select("posts.*, " +
 		"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, " +
 		"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.post_id = posts.id) as favorite_count, " +
-		"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count")
+		"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count, " +
+		repostCountColumn)
 
 	// 排序逻辑
 	orderClause := ""
@@ -96,50 +151,72 @@ func (s *PostService) GetPostList(page, pageSize int, tagName string, userID uin
 		orderClause += "view_count DESC"
 	case "likes":
 		orderClause += "like_count DESC"
+	case "hot":
+		orderClause += "hot_score DESC"
 	default:
 		orderClause += "posts.created_at DESC"
 	}
 
-	if err := db.Preload("User").Preload("Tags").Offset(offset).Limit(pageSize).Order(orderClause).Find(&posts).Error; err != nil {
+	if err := db.Preload("User").Preload("Tags").Preload("Category").Offset(offset).Limit(pageSize).Order(orderClause).Find(&posts).Error; err != nil {
 		return nil, 0, err
 	}
+	attachPostReactions(posts, viewer.UserID)
+	attachPostMentions(posts)
+	attachReposts(posts, viewer)
 
 	return posts, total, nil
 }
 
-func (s *PostService) GetHotPosts(limit int) ([]model.Post, error) {
+// GetHotPosts 获取热门文章：window（24h、7d、30d）内发布的文章按时间衰减后的热度排序
+func (s *PostService) GetHotPosts(limit int, window string) ([]model.Post, error) {
+	d, err := parseHotWindow(window)
+	if err != nil {
+		return nil, err
+	}
 	var posts []model.Post
-	// 热门文章逻辑：按点赞数排序，如果点赞数相同按评论数排序
-	// 这里需要关联查询点赞数和评论数
-	err := database.DB.Model(&model.Post{}).
+	db := database.DB.Model(&model.Post{}).
 		Select("posts.*, "+
 			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, "+
 			"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.post_id = posts.id) as favorite_count, "+
 			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count").
 		Where("status = ?", "published").
-		Order("like_count DESC, comment_count DESC").
+		Where("posts.created_at >= ?", time.Now().Add(-d)).
+		Where("posts.user_id IN (SELECT id FROM users WHERE is_private = ?)", false)
+	err = withoutPlainReposts(db).
+		Order("hot_score DESC, posts.created_at DESC").
 		Limit(limit).
 		Preload("User").
 		Find(&posts).Error
 	return posts, err
 }
 
-func (s *PostService) ToggleTop(id string, userId uint, userRole string) error {
+func (s *PostService) ToggleTop(id string, actor Actor) error {
 	var post model.Post
 	if err := database.DB.First(&post, id).Error; err != nil {
 		return errors.New("post not found")
 	}
 
 	// 只有作者或管理员可以操作个人置顶
-	if post.UserID != userId && userRole != "admin" {
+	if post.UserID != actor.UserID && !actor.IsAdmin() {
 		return errors.New("unauthorized")
 	}
 
-	return database.DB.Model(&post).Update("is_top", !post.IsTop).Error
+	oldValue := post.IsTop
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&post).Update("is_top", !oldValue).Error; err != nil {
+			return err
+		}
+		// 管理员操作他人文章需要留痕
+		if post.UserID != actor.UserID {
+			return auditService.record(tx, actor, "post.top", "post", post.ID,
+				map[string]bool{"is_top": oldValue}, map[string]bool{"is_top": !oldValue})
+		}
+		return nil
+	})
 }
 
-func (s *PostService) ToggleSystemTop(id string, userRole string) error {
-	if userRole != "admin" {
+func (s *PostService) ToggleSystemTop(id string, actor Actor) error {
+	if !actor.IsAdmin() {
 		return errors.New("only admin can toggle system top")
 	}
 
@@ -148,66 +225,140 @@ func (s *PostService) ToggleSystemTop(id string, userRole string) error {
 		return errors.New("post not found")
 	}
 
-	return database.DB.Model(&post).Update("is_system_top", !post.IsSystemTop).Error
+	oldValue := post.IsSystemTop
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&post).Update("is_system_top", !oldValue).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "post.system_top", "post", post.ID,
+			map[string]bool{"is_system_top": oldValue}, map[string]bool{"is_system_top": !oldValue})
+	})
 }
 
-func (s *PostService) UpdatePost(id string, userId uint, userRole string, updatedPost *model.Post) error {
+func (s *PostService) UpdatePost(id string, actor Actor, updatedPost *model.Post) error {
 	var post model.Post
 	if err := database.DB.Preload("Tags").First(&post, id).Error; err != nil {
 		return errors.New("post not found")
 	}
 
-	if post.UserID != userId && userRole != "admin" {
+	if post.UserID != actor.UserID && !actor.IsAdmin() {
 		return errors.New("unauthorized")
 	}
+	if isPlainRepost(&post) {
+		return errors.New("cannot edit a repost")
+	}
 
-	// 处理标签更新
-	var tags []model.Tag
-	for _, tagName := range updatedPost.TagNames {
-		var tag model.Tag
-		if err := database.DB.FirstOrCreate(&tag, model.Tag{Name: tagName}).Error; err != nil {
+	before := post
+
+	var mentioned []model.User
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 处理标签更新，使用 Association 替换标签
+		tags, err := resolveTags(tx, updatedPost.TagNames)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&post).Association("Tags").Replace(tags); err != nil {
 			return err
 		}
-		tags = append(tags, tag)
-	}
 
-	// 使用 Association 替换标签
-	if err := database.DB.Model(&post).Association("Tags").Replace(tags); err != nil {
-		return err
-	}
+		post.Title = updatedPost.Title
+		post.Content = updatedPost.Content
+		if updatedPost.Status != "" {
+			post.Status = updatedPost.Status
+		}
+		// 未传 category_id 时保持原分类，传 0 表示取消分类
+		if updatedPost.CategoryID != nil {
+			categoryID, err := validateCategory(tx, updatedPost.CategoryID)
+			if err != nil {
+				return err
+			}
+			post.CategoryID = categoryID
+		}
+		// 修改标题不会自动改变 slug，避免已分享的链接失效
+		if updatedPost.Slug != nil && *updatedPost.Slug != "" && (post.Slug == nil || *updatedPost.Slug != *post.Slug) {
+			if err := changeSlug(tx, &post, *updatedPost.Slug); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&post).Error; err != nil {
+			return err
+		}
+		// 只通知编辑后新增的提及
+		mentioned, err = mentionService.sync(tx, MentionSourcePost, post.ID, post.ID, post.UserID, post.Content, isPublished(&post))
+		if err != nil {
+			return err
+		}
 
-	post.Title = updatedPost.Title
-	post.Content = updatedPost.Content
-	if updatedPost.Status != "" {
-		post.Status = updatedPost.Status
+		// 管理员编辑他人文章需要留痕
+		if post.UserID != actor.UserID {
+			return auditService.record(tx, actor, "post.admin_update", "post", post.ID, before, post)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	return database.DB.Save(&post).Error
+	mentionService.notify(mentioned, post.UserID, post.UserID, post.ID, 0, post.Title, nil)
+	return nil
 }
 
-func (s *PostService) DeletePost(id string, userId uint, userRole string) error {
+func (s *PostService) DeletePost(id string, actor Actor) error {
 	var post model.Post
-	if err := database.DB.First(&post, id).Error; err != nil {
+	if err := database.DB.Preload("Tags").First(&post, id).Error; err != nil {
 		return errors.New("post not found")
 	}
 
-	if post.UserID != userId && userRole != "admin" {
+	if post.UserID != actor.UserID && !actor.IsAdmin() {
 		return errors.New("unauthorized")
 	}
 
-	return database.DB.Delete(&post).Error
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&post).Error; err != nil {
+			return err
+		}
+		// 从所属系列中移除
+		if err := tx.Where("post_id = ?", post.ID).Delete(&model.SeriesPost{}).Error; err != nil {
+			return err
+		}
+		if err := mentionService.removeSource(tx, MentionSourcePost, post.ID); err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "post.delete", "post", post.ID, post, nil)
+	})
 }
 
-func (s *PostService) GetAllTags() ([]model.Tag, error) {
+// GetAllTags 获取所有标签及其在已发布文章中的使用次数。
+// sort 可选 popular（按使用次数）、recent（按创建时间），默认按名称；limit 为 0 时不限制数量
+func (s *PostService) GetAllTags(sort string, limit int) ([]model.Tag, error) {
 	var tags []model.Tag
-	// 获取所有标签，可以按使用频率排序，这里简单起见直接获取所有
-	if err := database.DB.Find(&tags).Error; err != nil {
+	db := database.DB.Model(&model.Tag{}).
+		Select("tags.*, " +
+			"(SELECT COUNT(*) FROM post_tags JOIN posts ON posts.id = post_tags.post_id " +
+			"WHERE post_tags.tag_id = tags.id AND posts.status = 'published' AND posts.deleted_at IS NULL) as post_count")
+
+	switch sort {
+	case "popular":
+		db = db.Order("post_count DESC, tags.name ASC")
+	case "recent":
+		db = db.Order("tags.created_at DESC")
+	default:
+		db = db.Order("tags.name ASC")
+	}
+	if limit > 0 {
+		db = db.Limit(limit)
+	}
+
+	if err := db.Find(&tags).Error; err != nil {
 		return nil, err
 	}
 	return tags, nil
 }
 
-// LikePost 点赞文章
+// LikePost 点赞文章，只能点赞自己有权查看的文章
 func (s *PostService) LikePost(userID uint, postID uint) error {
+	if err := s.checkViewable(userID, postID); err != nil {
+		return err
+	}
 	return database.DB.Model(&model.User{Model: gorm.Model{ID: userID}}).
 		Association("LikedPosts").
 		Append(&model.Post{Model: gorm.Model{ID: postID}})
@@ -220,14 +371,14 @@ func (s *PostService) UnlikePost(userID uint, postID uint) error {
 		Delete(&model.Post{Model: gorm.Model{ID: postID}})
 }
 
-// GetLikedPosts 获取用户点赞的文章列表
-func (s *PostService) GetLikedPosts(userID uint) ([]*model.Post, error) {
+// GetLikedPosts 获取用户点赞的文章列表，不含 viewer 无权查看的私密账号文章
+func (s *PostService) GetLikedPosts(userID uint, viewer Actor) ([]*model.Post, error) {
 	var user model.User
 	// 预加载 LikedPosts 及其关联的 User 和 Tags 信息，并包含点赞数和评论数
 	err := database.DB.Preload("LikedPosts", func(db *gorm.DB) *gorm.DB {
-		return db.Select("posts.*, " +
-			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, " +
-			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count")
+		return visiblePosts(db.Select("posts.*, "+
+			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, "+
+			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count"), viewer)
 	}).Preload("LikedPosts.User").Preload("LikedPosts.Tags").First(&user, userID).Error
 	if err != nil {
 		return nil, err
@@ -251,28 +402,36 @@ func (s *PostService) GetPostLikeCount(postID uint) (int64, error) {
 	return count, err
 }
 
-// FavoritePost 收藏文章
+// FavoritePost 收藏文章，只能收藏自己有权查看的文章
 func (s *PostService) FavoritePost(userID uint, postID uint) error {
+	if err := s.checkViewable(userID, postID); err != nil {
+		return err
+	}
 	return database.DB.Model(&model.User{Model: gorm.Model{ID: userID}}).
 		Association("FavoritePosts").
 		Append(&model.Post{Model: gorm.Model{ID: postID}})
 }
 
-// UnfavoritePost 取消收藏
+// UnfavoritePost 取消收藏，同时从所有收藏夹中移除
 func (s *PostService) UnfavoritePost(userID uint, postID uint) error {
-	return database.DB.Model(&model.User{Model: gorm.Model{ID: userID}}).
-		Association("FavoritePosts").
-		Delete(&model.Post{Model: gorm.Model{ID: postID}})
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := collectionService.removeFavorite(tx, userID, postID); err != nil {
+			return err
+		}
+		return tx.Model(&model.User{Model: gorm.Model{ID: userID}}).
+			Association("FavoritePosts").
+			Delete(&model.Post{Model: gorm.Model{ID: postID}})
+	})
 }
 
-// GetFavoritePosts 获取用户收藏的文章列表
-func (s *PostService) GetFavoritePosts(userID uint) ([]*model.Post, error) {
+// GetFavoritePosts 获取用户收藏的文章列表，不含 viewer 无权查看的私密账号文章
+func (s *PostService) GetFavoritePosts(userID uint, viewer Actor) ([]*model.Post, error) {
 	var user model.User
 	err := database.DB.Preload("FavoritePosts", func(db *gorm.DB) *gorm.DB {
-		return db.Select("posts.*, " +
-			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, " +
-			"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.post_id = posts.id) as favorite_count, " +
-			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count")
+		return visiblePosts(db.Select("posts.*, "+
+			"(SELECT COUNT(*) FROM user_likes WHERE user_likes.post_id = posts.id) as like_count, "+
+			"(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.post_id = posts.id) as favorite_count, "+
+			"(SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id) as comment_count"), viewer)
 	}).Preload("FavoritePosts.User").Preload("FavoritePosts.Tags").First(&user, userID).Error
 	if err != nil {
 		return nil, err
@@ -303,3 +462,149 @@ func (s *PostService) SearchTags(keyword string, limit int) ([]model.Tag, error)
 		Find(&tags).Error
 	return tags, err
 }
+
+// assignSlug 为新文章设置 slug：作者指定时校验格式和唯一性，否则根据标题生成
+func assignSlug(tx *gorm.DB, post *model.Post) error {
+	if post.Slug != nil && *post.Slug != "" {
+		if !slug.Valid(*post.Slug) {
+			return errors.New("invalid slug")
+		}
+		if slugTaken(tx, post.UserID, *post.Slug, 0) {
+			return errors.New("slug already in use")
+		}
+		return nil
+	}
+	generated := uniqueSlug(tx, post.UserID, slug.Make(post.Title), 0)
+	post.Slug = &generated
+	return nil
+}
+
+// uniqueSlug 在作者范围内生成不重复的 slug，重复时追加序号
+func uniqueSlug(tx *gorm.DB, userID uint, base string, postID uint) string {
+	if base == "" {
+		base = "post"
+	}
+	candidate := base
+	for i := 2; slugTaken(tx, userID, candidate, postID); i++ {
+		suffix := "-" + strconv.Itoa(i)
+		if len(base)+len(suffix) > slug.MaxLength {
+			base = strings.TrimRight(base[:slug.MaxLength-len(suffix)], "-")
+		}
+		candidate = base + suffix
+	}
+	return candidate
+}
+
+// slugTaken 检查 slug 是否已被该作者的其他文章使用，旧 slug 同样保留用于跳转
+func slugTaken(tx *gorm.DB, userID uint, s string, postID uint) bool {
+	var count int64
+	tx.Unscoped().Model(&model.Post{}).Where("user_id = ? AND slug = ? AND id <> ?", userID, s, postID).Count(&count)
+	if count > 0 {
+		return true
+	}
+	tx.Model(&model.PostSlugRedirect{}).Where("user_id = ? AND slug = ? AND post_id <> ?", userID, s, postID).Count(&count)
+	return count > 0
+}
+
+// changeSlug 修改文章 slug，旧 slug 保留为跳转
+func changeSlug(tx *gorm.DB, post *model.Post, newSlug string) error {
+	if !slug.Valid(newSlug) {
+		return errors.New("invalid slug")
+	}
+	if slugTaken(tx, post.UserID, newSlug, post.ID) {
+		return errors.New("slug already in use")
+	}
+
+	// 改回以前用过的 slug 时删除对应的跳转记录
+	if err := tx.Unscoped().Where("user_id = ? AND slug = ?", post.UserID, newSlug).Delete(&model.PostSlugRedirect{}).Error; err != nil {
+		return err
+	}
+	if post.Slug != nil && *post.Slug != "" {
+		redirect := model.PostSlugRedirect{UserID: post.UserID, Slug: *post.Slug, PostID: post.ID}
+		if err := tx.Create(&redirect).Error; err != nil {
+			return err
+		}
+	}
+	post.Slug = &newSlug
+	return nil
+}
+
+// ResolveSlug 根据作者和 slug 查找文章，返回文章ID和当前 slug；旧 slug 会解析到对应文章
+func (s *PostService) ResolveSlug(userID uint, postSlug string) (uint, string, error) {
+	var post model.Post
+	if err := database.DB.Where("user_id = ? AND slug = ?", userID, postSlug).First(&post).Error; err == nil {
+		return post.ID, postSlug, nil
+	}
+
+	var redirect model.PostSlugRedirect
+	if err := database.DB.Where("user_id = ? AND slug = ?", userID, postSlug).First(&redirect).Error; err != nil {
+		return 0, "", errors.New("post not found")
+	}
+	if err := database.DB.First(&post, redirect.PostID).Error; err != nil || post.Slug == nil {
+		return 0, "", errors.New("post not found")
+	}
+	return post.ID, *post.Slug, nil
+}
+
+// BackfillSlugs 为引入 slug 之前创建的文章生成 slug
+func (s *PostService) BackfillSlugs() {
+	for {
+		var posts []model.Post
+		if err := database.DB.Unscoped().Where("slug IS NULL").Limit(100).Find(&posts).Error; err != nil {
+			fmt.Printf("PostService: failed to load posts without slug: %v\n", err)
+			return
+		}
+		if len(posts) == 0 {
+			return
+		}
+		for _, post := range posts {
+			generated := uniqueSlug(database.DB, post.UserID, slug.Make(post.Title), post.ID)
+			if err := database.DB.Unscoped().Model(&model.Post{}).Where("id = ?", post.ID).Update("slug", generated).Error; err != nil {
+				fmt.Printf("PostService: failed to backfill slug for post %d: %v\n", post.ID, err)
+				return
+			}
+		}
+	}
+}
+
+// repostCountColumn 统计未删除的转发和引用转发数
+const repostCountColumn = "(SELECT COUNT(*) FROM posts reposts WHERE reposts.repost_of_id = posts.id AND reposts.deleted_at IS NULL) as repost_count"
+
+// isPublished 判断文章是否已发布，新建文章未指定状态时使用数据库默认值 published
+func isPublished(post *model.Post) bool {
+	return post.Status == "" || post.Status == "published"
+}
+
+// visiblePosts 限制查询结果为 viewer 可以查看的文章：私密账号的文章只有作者本人、其关注者和管理员可见
+func visiblePosts(db *gorm.DB, viewer Actor) *gorm.DB {
+	if viewer.IsAdmin() {
+		return db
+	}
+	return db.Where("(posts.user_id IN (SELECT id FROM users WHERE is_private = ?) OR posts.user_id = ? "+
+		"OR posts.user_id IN (SELECT followed_id FROM user_followers WHERE follower_id = ?))",
+		false, viewer.UserID, viewer.UserID)
+}
+
+// checkViewable 文章不存在或 userID 无权查看时返回 post not found
+func (s *PostService) checkViewable(userID, postID uint) error {
+	var post model.Post
+	if err := database.DB.Select("id", "user_id").First(&post, postID).Error; err != nil || !canViewAuthor(post.UserID, Actor{UserID: userID}) {
+		return errors.New("post not found")
+	}
+	return nil
+}
+
+// canViewAuthor 判断 viewer 能否查看 authorID 的文章及其评论
+func canViewAuthor(authorID uint, viewer Actor) bool {
+	if viewer.IsAdmin() || authorID == viewer.UserID {
+		return true
+	}
+	var author model.User
+	if err := database.DB.Select("id", "is_private").First(&author, authorID).Error; err != nil {
+		return false
+	}
+	if !author.IsPrivate {
+		return true
+	}
+	return userService.IsFollowing(viewer.UserID, authorID)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+)
+
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// ReactionService 文章和评论的表情回应
+type ReactionService struct{}
+
+var reactionService = new(ReactionService)
+
+// Emojis 返回当前可用的表情，顺序即展示顺序
+func (s *ReactionService) Emojis() []string {
+	var emojis []string
+	for _, item := range strings.Split(settingService.Get(SettingReactionEmojis), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			emojis = append(emojis, item)
+		}
+	}
+	return emojis
+}
+
+// Toggle 添加或取消表情回应，返回操作后是否处于已回应状态
+func (s *ReactionService) Toggle(userID uint, targetType string, targetID uint, emoji string) (bool, error) {
+	if !s.allowed(emoji) {
+		return false, errors.New("unsupported emoji")
+	}
+	ownerID, err := s.targetOwner(targetType, targetID, userID)
+	if err != nil {
+		return false, err
+	}
+	if blockService.HasBlocked(ownerID, userID) {
+		return false, errors.New("blocked")
+	}
+
+	result := database.DB.Where("user_id = ? AND target_type = ? AND target_id = ? AND emoji = ?", userID, targetType, targetID, emoji).
+		Delete(&model.Reaction{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return false, nil
+	}
+	reaction := model.Reaction{UserID: userID, TargetType: targetType, TargetID: targetID, Emoji: emoji}
+	if err := database.DB.Create(&reaction).Error; err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Summaries 批量统计多个对象的表情回应，viewerID 为 0 时 Reacted 均为 false。
+// 已从可用列表中移除的表情仍会统计，排在可用表情之后
+func (s *ReactionService) Summaries(targetType string, targetIDs []uint, viewerID uint) map[uint][]model.ReactionSummary {
+	summaries := make(map[uint][]model.ReactionSummary)
+	if len(targetIDs) == 0 {
+		return summaries
+	}
+
+	var rows []struct {
+		TargetID uint
+		Emoji    string
+		Count    int64
+		Reacted  int64
+	}
+	err := database.DB.Model(&model.Reaction{}).
+		Select("target_id, emoji, COUNT(*) as count, SUM(CASE WHEN user_id = ? THEN 1 ELSE 0 END) as reacted", viewerID).
+		Where("target_type = ? AND target_id IN ?", targetType, targetIDs).
+		Group("target_id, emoji").
+		Scan(&rows).Error
+	if err != nil {
+		fmt.Printf("ReactionService: failed to load %s reactions: %v\n", targetType, err)
+		return summaries
+	}
+
+	rank := make(map[string]int)
+	for i, emoji := range s.Emojis() {
+		rank[emoji] = i + 1
+	}
+	for _, row := range rows {
+		summaries[row.TargetID] = append(summaries[row.TargetID], model.ReactionSummary{
+			Emoji:   row.Emoji,
+			Count:   row.Count,
+			Reacted: viewerID != 0 && row.Reacted > 0,
+		})
+	}
+	for id, list := range summaries {
+		sortReactionSummaries(list, rank)
+		summaries[id] = list
+	}
+	return summaries
+}
+
+// attachPostReactions 为文章填充表情回应统计
+func attachPostReactions(posts []model.Post, viewerID uint) {
+	ids := make([]uint, len(posts))
+	for i := range posts {
+		ids[i] = posts[i].ID
+	}
+	summaries := reactionService.Summaries(ReactionTargetPost, ids, viewerID)
+	for i := range posts {
+		posts[i].Reactions = summaries[posts[i].ID]
+	}
+}
+
+// attachCommentReactions 为评论及其回复填充表情回应统计
+func attachCommentReactions(comments []model.Comment, viewerID uint) {
+	var ids []uint
+	for i := range comments {
+		ids = append(ids, comments[i].ID)
+		for j := range comments[i].Replies {
+			ids = append(ids, comments[i].Replies[j].ID)
+		}
+	}
+	summaries := reactionService.Summaries(ReactionTargetComment, ids, viewerID)
+	for i := range comments {
+		comments[i].Reactions = summaries[comments[i].ID]
+		for j := range comments[i].Replies {
+			comments[i].Replies[j].Reactions = summaries[comments[i].Replies[j].ID]
+		}
+	}
+}
+
+// targetOwner 返回被回应对象的作者，对象不存在或 userID 无权查看时返回错误
+func (s *ReactionService) targetOwner(targetType string, targetID, userID uint) (uint, error) {
+	var postID uint
+	var ownerID uint
+	switch targetType {
+	case ReactionTargetPost:
+		postID = targetID
+	case ReactionTargetComment:
+		var comment model.Comment
+		if err := database.DB.Select("id", "user_id", "post_id").First(&comment, targetID).Error; err != nil {
+			return 0, errors.New("comment not found")
+		}
+		postID = comment.PostID
+		ownerID = comment.UserID
+	default:
+		return 0, errors.New("invalid target")
+	}
+
+	var post model.Post
+	if err := database.DB.Select("id", "user_id").First(&post, postID).Error; err != nil || !canViewAuthor(post.UserID, Actor{UserID: userID}) {
+		return 0, errors.New("post not found")
+	}
+	if ownerID == 0 {
+		ownerID = post.UserID
+	}
+	return ownerID, nil
+}
+
+func (s *ReactionService) allowed(emoji string) bool {
+	for _, e := range s.Emojis() {
+		if e == emoji {
+			return true
+		}
+	}
+	return false
+}
+
+// sortReactionSummaries 按可用表情的配置顺序排列，不在列表中的排在最后
+func sortReactionSummaries(list []model.ReactionSummary, rank map[string]int) {
+	order := func(emoji string) int {
+		if r, ok := rank[emoji]; ok {
+			return r
+		}
+		return len(rank) + 1
+	}
+	for i := 1; i < len(list); i++ {
+		for j := i; j > 0 && order(list[j].Emoji) < order(list[j-1].Emoji); j-- {
+			list[j], list[j-1] = list[j-1], list[j]
+		}
+	}
+}
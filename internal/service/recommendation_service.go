@@ -0,0 +1,539 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+const (
+	recommendationRefreshInterval = time.Hour
+	recommendationCorpusSize      = 5000 // 参与相关文章计算的最近文章数
+	maxRelatedPosts               = 10
+	maxUserRecommendations        = 20
+	maxTermsPerPost               = 40 // 每篇文章只保留权重最高的词用于相似度计算
+	maxCoLikers                   = 200
+	maxFolloweesScanned           = 500                 // 计算共同关注时每个用户最多查看的关注数
+	recommendationActiveWindow    = 30 * 24 * time.Hour // 只为最近活跃过的用户计算推荐关注
+	recommendationLockName        = "recommendations"
+
+	relatedContentWeight = 0.7 // 相关度 = 内容相似度和标签重合度的加权和
+	relatedTagWeight     = 0.3
+	titleTermBoost       = 3 // 标题中的词按出现 3 次计算
+
+	coFollowWeight = 1.0
+	coLikeWeight   = 0.5
+)
+
+// RecommendationService 相关文章和推荐关注。结果由后台任务定期计算并保存，接口只读取计算结果
+type RecommendationService struct{}
+
+var recommendationService = new(RecommendationService)
+
+// StartRecommendationWorker 启动后台任务，定期重新计算相关文章和推荐关注
+func StartRecommendationWorker() {
+	go func() {
+		ticker := time.NewTicker(recommendationRefreshInterval)
+		defer ticker.Stop()
+		for {
+			recommendationService.Refresh()
+			<-ticker.C
+		}
+	}()
+}
+
+// Refresh 重新计算所有结果。多实例部署时每个周期只有取得任务锁的实例执行
+func (s *RecommendationService) Refresh() {
+	if !tryJobLock(recommendationLockName, recommendationRefreshInterval-time.Minute) {
+		return
+	}
+	if err := s.refreshRelatedPosts(); err != nil {
+		fmt.Printf("RecommendationService: failed to refresh related posts: %v\n", err)
+	}
+	if err := s.refreshUserRecommendations(); err != nil {
+		fmt.Printf("RecommendationService: failed to refresh user recommendations: %v\n", err)
+	}
+}
+
+// RelatedPosts 返回文章的相关文章，只包含 viewer 可以查看的已发布文章。
+// 新文章尚未计算时按共同标签数临时查找
+func (s *RecommendationService) RelatedPosts(postID uint, viewer Actor, limit int) ([]model.Post, error) {
+	var post model.Post
+	if err := database.DB.Select("id", "user_id").First(&post, postID).Error; err != nil || !canViewAuthor(post.UserID, viewer) {
+		return nil, errors.New("post not found")
+	}
+	if limit <= 0 || limit > maxRelatedPosts {
+		limit = maxRelatedPosts
+	}
+
+	db := database.DB.Model(&model.Post{}).
+		Joins("JOIN related_posts ON related_posts.related_id = posts.id AND related_posts.post_id = ?", postID).
+		Where("posts.status = ?", "published")
+	db = excludeHiddenUsers(db, viewer.UserID, "posts.user_id", BlockKindBlock)
+	var posts []model.Post
+	err := visiblePosts(db, viewer).Preload("User").Preload("Tags").
+		Order("related_posts.position asc").
+		Limit(limit).
+		Find(&posts).Error
+	if err != nil || len(posts) > 0 {
+		return posts, err
+	}
+
+	db = database.DB.Model(&model.Post{}).
+		Select("posts.*, COUNT(*) as shared_tags").
+		Joins("JOIN post_tags ON post_tags.post_id = posts.id").
+		Where("post_tags.tag_id IN (SELECT tag_id FROM post_tags WHERE post_id = ?)", postID).
+		Where("posts.id <> ? AND posts.status = ? AND posts.repost_of_id IS NULL", postID, "published")
+	db = excludeHiddenUsers(db, viewer.UserID, "posts.user_id", BlockKindBlock)
+	err = visiblePosts(db, viewer).Preload("User").Preload("Tags").
+		Group("posts.id").
+		Order("shared_tags DESC, posts.created_at DESC").
+		Limit(limit).
+		Find(&posts).Error
+	return posts, err
+}
+
+// UserRecommendations 返回推荐关注的用户，过滤掉计算之后已关注或屏蔽的用户。
+// 没有计算结果（如新用户）时推荐粉丝最多的作者
+func (s *RecommendationService) UserRecommendations(userID uint, limit int) ([]model.UserRecommendation, error) {
+	if limit <= 0 || limit > maxUserRecommendations {
+		limit = maxUserRecommendations
+	}
+
+	var list []model.UserRecommendation
+	db := database.DB.Model(&model.UserRecommendation{}).
+		Joins("JOIN users ON users.id = user_recommendations.candidate_id AND users.deleted_at IS NULL").
+		Where("user_recommendations.user_id = ?", userID).
+		Where("user_recommendations.candidate_id NOT IN (SELECT followed_id FROM user_followers WHERE follower_id = ?)", userID).
+		Where("user_recommendations.candidate_id NOT IN (SELECT user_id FROM user_blocks WHERE blocked_id = ? AND kind = ?)", userID, BlockKindBlock)
+	db = excludeHiddenUsers(db, userID, "user_recommendations.candidate_id", BlockKindBlock, BlockKindMute)
+	err := db.Preload("Candidate").
+		Order("user_recommendations.score DESC").
+		Limit(limit).
+		Find(&list).Error
+	if err != nil || len(list) > 0 {
+		return list, err
+	}
+
+	var popular []struct {
+		ID    uint
+		Count int64
+	}
+	db = database.DB.Table("user_followers").
+		Select("followed_id as id, COUNT(*) as count").
+		Joins("JOIN users ON users.id = user_followers.followed_id AND users.deleted_at IS NULL").
+		Where("followed_id <> ?", userID).
+		Where("followed_id NOT IN (SELECT followed_id FROM user_followers WHERE follower_id = ?)", userID).
+		Where("followed_id NOT IN (SELECT user_id FROM user_blocks WHERE blocked_id = ? AND kind = ?)", userID, BlockKindBlock)
+	db = excludeHiddenUsers(db, userID, "followed_id", BlockKindBlock, BlockKindMute)
+	if err := db.Group("followed_id").Order("count DESC").Limit(limit).Scan(&popular).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(popular))
+	for i, p := range popular {
+		ids[i] = p.ID
+	}
+	var users []model.User
+	database.DB.Where("id IN ?", ids).Find(&users)
+	byID := make(map[uint]model.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+	for _, p := range popular {
+		if user, ok := byID[p.ID]; ok {
+			list = append(list, model.UserRecommendation{CandidateID: p.ID, Candidate: user, Score: float64(p.Count), Reason: "popular"})
+		}
+	}
+	return list, nil
+}
+
+// refreshRelatedPosts 按标题和正文的 TF-IDF 余弦相似度与标签的 Jaccard 系数计算相关文章
+func (s *RecommendationService) refreshRelatedPosts() error {
+	var posts []model.Post
+	err := database.DB.Model(&model.Post{}).
+		Select("id", "title", "content").
+		Where("status = ? AND repost_of_id IS NULL", "published").
+		Order("created_at DESC").
+		Limit(recommendationCorpusSize).
+		Find(&posts).Error
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uint, len(posts))
+	docs := make([][]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+		for j := 0; j < titleTermBoost; j++ {
+			docs[i] = append(docs[i], tokenize(post.Title)...)
+		}
+		docs[i] = append(docs[i], tokenize(post.Content)...)
+	}
+	vectors := tfidf(docs)
+
+	var postTags []struct {
+		PostID uint
+		TagID  uint
+	}
+	if len(ids) > 0 {
+		database.DB.Table("post_tags").Select("post_id, tag_id").Where("post_id IN ?", ids).Scan(&postTags)
+	}
+	index := make(map[uint]int, len(ids))
+	for i, id := range ids {
+		index[id] = i
+	}
+	tagsOf := make([]map[uint]bool, len(posts))
+	postsWithTag := make(map[uint][]int)
+	for _, pt := range postTags {
+		i := index[pt.PostID]
+		if tagsOf[i] == nil {
+			tagsOf[i] = make(map[uint]bool)
+		}
+		tagsOf[i][pt.TagID] = true
+		postsWithTag[pt.TagID] = append(postsWithTag[pt.TagID], i)
+	}
+
+	// 倒排索引，只需比较至少有一个共同词或共同标签的文章
+	postings := make(map[string][]int)
+	for i, vector := range vectors {
+		for term := range vector {
+			postings[term] = append(postings[term], i)
+		}
+	}
+
+	var rows []model.RelatedPost
+	for i := range posts {
+		content := make(map[int]float64)
+		for term, weight := range vectors[i] {
+			for _, j := range postings[term] {
+				if j != i {
+					content[j] += weight * vectors[j][term]
+				}
+			}
+		}
+		candidates := make(map[int]bool, len(content))
+		for j := range content {
+			candidates[j] = true
+		}
+		for tag := range tagsOf[i] {
+			for _, j := range postsWithTag[tag] {
+				if j != i {
+					candidates[j] = true
+				}
+			}
+		}
+
+		type scored struct {
+			index int
+			score float64
+		}
+		var ranked []scored
+		for j := range candidates {
+			score := relatedContentWeight*content[j] + relatedTagWeight*jaccard(tagsOf[i], tagsOf[j])
+			if score > 0 {
+				ranked = append(ranked, scored{j, score})
+			}
+		}
+		sort.Slice(ranked, func(a, b int) bool {
+			if ranked[a].score != ranked[b].score {
+				return ranked[a].score > ranked[b].score
+			}
+			return ids[ranked[a].index] > ids[ranked[b].index]
+		})
+		if len(ranked) > maxRelatedPosts {
+			ranked = ranked[:maxRelatedPosts]
+		}
+		for rank, r := range ranked {
+			rows = append(rows, model.RelatedPost{PostID: ids[i], RelatedID: ids[r.index], Score: r.score, Position: rank + 1})
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.RelatedPost{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+// refreshUserRecommendations 根据共同关注（关注的人也关注了谁）和共同点赞
+// （喜欢相同文章的人还喜欢谁的文章）为最近活跃且有关注或点赞记录的用户计算推荐关注，
+// 其余用户使用热门作者
+func (s *RecommendationService) refreshUserRecommendations() error {
+	var active []uint
+	err := database.DB.Table("user_sessions").
+		Where("last_seen_at >= ?", time.Now().Add(-recommendationActiveWindow)).
+		Distinct().Pluck("user_id", &active).Error
+	if err != nil {
+		return err
+	}
+	var follows []struct {
+		FollowerID uint
+		FollowedID uint
+	}
+	if err := database.DB.Table("user_followers").Select("follower_id, followed_id").Scan(&follows).Error; err != nil {
+		return err
+	}
+	var likes []struct {
+		UserID uint
+		PostID uint
+		Author uint
+	}
+	err = database.DB.Table("user_likes").
+		Select("user_likes.user_id, user_likes.post_id, posts.user_id as author").
+		Joins("JOIN posts ON posts.id = user_likes.post_id AND posts.deleted_at IS NULL AND posts.status = 'published'").
+		Scan(&likes).Error
+	if err != nil {
+		return err
+	}
+	var blocks []model.UserBlock
+	if err := database.DB.Where("kind = ?", BlockKindBlock).Find(&blocks).Error; err != nil {
+		return err
+	}
+
+	following := make(map[uint]map[uint]bool)
+	for _, f := range follows {
+		if following[f.FollowerID] == nil {
+			following[f.FollowerID] = make(map[uint]bool)
+		}
+		following[f.FollowerID][f.FollowedID] = true
+	}
+	likedPosts := make(map[uint][]uint)
+	likers := make(map[uint][]uint)
+	authorOf := make(map[uint]uint)
+	for _, l := range likes {
+		likedPosts[l.UserID] = append(likedPosts[l.UserID], l.PostID)
+		likers[l.PostID] = append(likers[l.PostID], l.UserID)
+		authorOf[l.PostID] = l.Author
+	}
+	blocked := make(map[[2]uint]bool)
+	for _, b := range blocks {
+		blocked[[2]uint{b.UserID, b.BlockedID}] = true
+		blocked[[2]uint{b.BlockedID, b.UserID}] = true
+	}
+
+	var rows []model.UserRecommendation
+	for _, userID := range active {
+		if len(following[userID]) == 0 && len(likedPosts[userID]) == 0 {
+			continue
+		}
+
+		// 关注数超过上限时只查看 ID 最小的一部分，避免关注了大量用户时计算量过大
+		followees := make([]uint, 0, len(following[userID]))
+		for followed := range following[userID] {
+			followees = append(followees, followed)
+		}
+		sort.Slice(followees, func(a, b int) bool { return followees[a] < followees[b] })
+		if len(followees) > maxFolloweesScanned {
+			followees = followees[:maxFolloweesScanned]
+		}
+		coFollow := make(map[uint]float64)
+		for _, followed := range followees {
+			for candidate := range following[followed] {
+				coFollow[candidate] += coFollowWeight
+			}
+		}
+
+		// 与该用户点赞过相同文章最多的用户
+		overlap := make(map[uint]int)
+		for _, postID := range likedPosts[userID] {
+			for _, other := range likers[postID] {
+				if other != userID {
+					overlap[other]++
+				}
+			}
+		}
+		coLikers := make([]uint, 0, len(overlap))
+		for other := range overlap {
+			coLikers = append(coLikers, other)
+		}
+		sort.Slice(coLikers, func(a, b int) bool {
+			if overlap[coLikers[a]] != overlap[coLikers[b]] {
+				return overlap[coLikers[a]] > overlap[coLikers[b]]
+			}
+			return coLikers[a] < coLikers[b]
+		})
+		if len(coLikers) > maxCoLikers {
+			coLikers = coLikers[:maxCoLikers]
+		}
+		coLike := make(map[uint]float64)
+		for _, other := range coLikers {
+			authors := make(map[uint]bool)
+			for _, postID := range likedPosts[other] {
+				authors[authorOf[postID]] = true
+			}
+			for author := range authors {
+				coLike[author] += coLikeWeight
+			}
+		}
+
+		type scored struct {
+			id     uint
+			score  float64
+			reason string
+		}
+		var ranked []scored
+		seen := make(map[uint]bool)
+		for _, source := range []map[uint]float64{coFollow, coLike} {
+			for candidate := range source {
+				if seen[candidate] || candidate == userID || following[userID][candidate] || blocked[[2]uint{userID, candidate}] {
+					continue
+				}
+				seen[candidate] = true
+				reason := "co_follow"
+				if coLike[candidate] > coFollow[candidate] {
+					reason = "co_like"
+				}
+				ranked = append(ranked, scored{candidate, coFollow[candidate] + coLike[candidate], reason})
+			}
+		}
+		sort.Slice(ranked, func(a, b int) bool {
+			if ranked[a].score != ranked[b].score {
+				return ranked[a].score > ranked[b].score
+			}
+			return ranked[a].id < ranked[b].id
+		})
+		if len(ranked) > maxUserRecommendations {
+			ranked = ranked[:maxUserRecommendations]
+		}
+		for _, r := range ranked {
+			rows = append(rows, model.UserRecommendation{UserID: userID, CandidateID: r.id, Score: r.score, Reason: r.reason})
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&model.UserRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+}
+
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true, "you": true,
+	"with": true, "this": true, "that": true, "from": true, "have": true, "was": true, "were": true,
+	"http": true, "https": true, "www": true, "com": true, "的": true, "了": true, "是": true,
+}
+
+// tokenize 将文本切分为词：英文等按单词切分并转为小写，中文按相邻两字切分
+func tokenize(text string) []string {
+	var tokens []string
+	var word, han []rune
+	flushWord := func() {
+		if len(word) >= 2 {
+			token := string(word)
+			if !stopWords[token] && strings.TrimFunc(token, unicode.IsDigit) != "" {
+				tokens = append(tokens, token)
+			}
+		}
+		word = word[:0]
+	}
+	flushHan := func() {
+		if len(han) == 1 && !stopWords[string(han)] {
+			tokens = append(tokens, string(han))
+		}
+		for i := 0; i+1 < len(han); i++ {
+			tokens = append(tokens, string(han[i:i+2]))
+		}
+		han = han[:0]
+	}
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			han = append(han, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushHan()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushHan()
+		}
+	}
+	flushWord()
+	flushHan()
+	return tokens
+}
+
+// tfidf 计算每篇文档归一化后的 TF-IDF 向量，只保留权重最高的 maxTermsPerPost 个词。
+// 出现在超过一半文档中的词区分度太低，不参与计算
+func tfidf(docs [][]string) []map[string]float64 {
+	df := make(map[string]int)
+	counts := make([]map[string]int, len(docs))
+	for i, doc := range docs {
+		counts[i] = make(map[string]int)
+		for _, token := range doc {
+			counts[i][token]++
+		}
+		for token := range counts[i] {
+			df[token]++
+		}
+	}
+
+	n := float64(len(docs))
+	vectors := make([]map[string]float64, len(docs))
+	for i := range docs {
+		type weighted struct {
+			term   string
+			weight float64
+		}
+		var terms []weighted
+		for term, count := range counts[i] {
+			if len(docs) > 2 && float64(df[term]) > n/2 {
+				continue
+			}
+			idf := math.Log(n / float64(df[term]))
+			if idf <= 0 {
+				continue
+			}
+			terms = append(terms, weighted{term, (1 + math.Log(float64(count))) * idf})
+		}
+		sort.Slice(terms, func(a, b int) bool {
+			if terms[a].weight != terms[b].weight {
+				return terms[a].weight > terms[b].weight
+			}
+			return terms[a].term < terms[b].term
+		})
+		if len(terms) > maxTermsPerPost {
+			terms = terms[:maxTermsPerPost]
+		}
+
+		var norm float64
+		for _, t := range terms {
+			norm += t.weight * t.weight
+		}
+		norm = math.Sqrt(norm)
+		vectors[i] = make(map[string]float64, len(terms))
+		for _, t := range terms {
+			vectors[i][t.term] = t.weight / norm
+		}
+	}
+	return vectors
+}
+
+func jaccard(a, b map[uint]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for id := range a {
+		if b[id] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
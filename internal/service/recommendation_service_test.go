@@ -0,0 +1,124 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"english words are lowercased", "Hello, World", []string{"hello", "world"}},
+		{"english stop words and numbers are dropped", "the 2024 release of Go", []string{"release", "of", "go"}},
+		{"single letters are dropped", "a b cd", []string{"cd"}},
+		{"chinese is split into bigrams", "我的博客", []string{"我的", "的博", "博客"}},
+		{"single chinese character is kept", "好", []string{"好"}},
+		{"single chinese stop word is dropped", "的", nil},
+		{"mixed scripts", "Go语言入门", []string{"go", "语言", "言入", "入门"}},
+		{"punctuation splits chinese runs", "你好，世界", []string{"你好", "世界"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTFIDF(t *testing.T) {
+	tests := []struct {
+		name    string
+		docs    [][]string
+		doc     int
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "terms in more than half of the documents are dropped",
+			docs:    [][]string{{"common", "go"}, {"common", "rust"}, {"common", "java"}, {"python"}},
+			doc:     0,
+			present: []string{"go"},
+			absent:  []string{"common"},
+		},
+		{
+			name:    "terms in exactly half of the documents are kept",
+			docs:    [][]string{{"shared", "go"}, {"shared", "rust"}, {"java"}, {"python"}},
+			doc:     0,
+			present: []string{"shared", "go"},
+		},
+		{
+			name:   "terms in every document carry no weight",
+			docs:   [][]string{{"common"}, {"common"}},
+			doc:    0,
+			absent: []string{"common"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vector := tfidf(tt.docs)[tt.doc]
+			for _, term := range tt.present {
+				if vector[term] <= 0 {
+					t.Errorf("expected %q in vector %v", term, vector)
+				}
+			}
+			for _, term := range tt.absent {
+				if _, ok := vector[term]; ok {
+					t.Errorf("expected %q to be dropped from vector %v", term, vector)
+				}
+			}
+			if len(vector) > 0 {
+				var norm float64
+				for _, w := range vector {
+					norm += w * w
+				}
+				if math.Abs(norm-1) > 1e-9 {
+					t.Errorf("vector is not normalized: |v|^2 = %f", norm)
+				}
+			}
+		})
+	}
+}
+
+func TestTFIDFKeepsTopTerms(t *testing.T) {
+	var doc []string
+	for i := 0; i < maxTermsPerPost+10; i++ {
+		doc = append(doc, string(rune('a'+i/26))+string(rune('a'+i%26)))
+	}
+	vectors := tfidf([][]string{doc, {"other"}, {"another"}})
+	if len(vectors[0]) != maxTermsPerPost {
+		t.Fatalf("got %d terms, want %d", len(vectors[0]), maxTermsPerPost)
+	}
+}
+
+func TestJaccard(t *testing.T) {
+	set := func(ids ...uint) map[uint]bool {
+		m := make(map[uint]bool)
+		for _, id := range ids {
+			m[id] = true
+		}
+		return m
+	}
+	tests := []struct {
+		name string
+		a, b map[uint]bool
+		want float64
+	}{
+		{"identical", set(1, 2), set(1, 2), 1},
+		{"disjoint", set(1), set(2), 0},
+		{"partial overlap", set(1, 2, 3), set(2, 3, 4), 0.5},
+		{"empty set", set(), set(1), 0},
+		{"nil set", nil, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jaccard(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("jaccard = %f, want %f", got, tt.want)
+			}
+		})
+	}
+}
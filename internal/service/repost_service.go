@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RepostService 转发和引用转发。转发本身是一篇 repost_of_id 指向原文的文章，
+// 因此会出现在转发者的主页和关注者的时间线中
+type RepostService struct{}
+
+var repostService = new(RepostService)
+
+// ToggleRepost 直接转发文章，已转发时取消转发。转发一篇直接转发时转发的是其原文
+func (s *RepostService) ToggleRepost(userID, postID uint) (bool, error) {
+	original, err := s.original(userID, postID)
+	if err != nil {
+		return false, err
+	}
+
+	reposted := false
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定转发者的用户行，同一用户的并发转发请求依次执行，避免重复转发
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&model.User{}, userID).Error; err != nil {
+			return err
+		}
+		result := tx.Where("user_id = ? AND repost_of_id = ? AND content = ?", userID, original.ID, "").Delete(&model.Post{})
+		if result.Error != nil || result.RowsAffected > 0 {
+			return result.Error
+		}
+		reposted = true
+		return tx.Create(&model.Post{UserID: userID, RepostOfID: &original.ID, Status: "published"}).Error
+	})
+	if err != nil || !reposted {
+		return false, err
+	}
+	if original.UserID != userID {
+		notificationService.CreateNotification(&model.Notification{
+			UserID:     original.UserID,
+			Type:       "repost",
+			Content:    original.Title,
+			FromUserID: userID,
+			PostID:     original.ID,
+		})
+	}
+	return true, nil
+}
+
+// Quote 引用转发，content 为转发时附加的评论，可以多次引用同一篇文章
+func (s *RepostService) Quote(userID, postID uint, content string) (*model.Post, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, errors.New("content is required")
+	}
+	original, err := s.original(userID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := model.Post{UserID: userID, Content: content, RepostOfID: &original.ID, Status: "published"}
+	var mentioned []model.User
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&quote).Error; err != nil {
+			return err
+		}
+		var err error
+		mentioned, err = mentionService.sync(tx, MentionSourcePost, quote.ID, quote.ID, userID, content, true)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	notified := make(map[uint]bool)
+	if original.UserID != userID {
+		notified[original.UserID] = true
+		notificationService.CreateNotification(&model.Notification{
+			UserID:     original.UserID,
+			Type:       "quote",
+			Content:    content,
+			FromUserID: userID,
+			PostID:     quote.ID,
+		})
+	}
+	mentionService.notify(mentioned, userID, userID, quote.ID, 0, content, notified)
+	return &quote, nil
+}
+
+// original 查找可以被 userID 转发的原文：已发布、userID 有权查看且未被作者屏蔽。
+// 私密账号的文章只对关注者可见，不能转发
+func (s *RepostService) original(userID, postID uint) (*model.Post, error) {
+	var post model.Post
+	if err := database.DB.First(&post, postID).Error; err != nil {
+		return nil, errors.New("post not found")
+	}
+	if isPlainRepost(&post) {
+		if err := database.DB.First(&post, *post.RepostOfID).Error; err != nil {
+			return nil, errors.New("post not found")
+		}
+	}
+	if post.Status != "published" || !canViewAuthor(post.UserID, Actor{UserID: userID}) {
+		return nil, errors.New("post not found")
+	}
+	if blockService.HasBlocked(post.UserID, userID) {
+		return nil, errors.New("blocked")
+	}
+	var author model.User
+	if err := database.DB.Select("id", "is_private").First(&author, post.UserID).Error; err != nil || author.IsPrivate {
+		return nil, errors.New("cannot repost private post")
+	}
+	return &post, nil
+}
+
+// isPlainRepost 判断是否为不带评论的直接转发
+func isPlainRepost(post *model.Post) bool {
+	return post.RepostOfID != nil && post.Content == ""
+}
+
+// withoutPlainReposts 排除直接转发，用于首页、搜索等不按作者筛选的列表
+func withoutPlainReposts(db *gorm.DB) *gorm.DB {
+	return db.Where("(posts.repost_of_id IS NULL OR posts.content <> '')")
+}
+
+// withoutOrphanReposts 排除原文已删除、隐藏、viewer 无权查看或屏蔽了原文作者的直接转发，
+// 与 attachReposts 判断原文不可用的条件一致；引用转发仍会显示，原文标记为不可用
+func withoutOrphanReposts(db *gorm.DB, viewer Actor) *gorm.DB {
+	originals := database.DB.Model(&model.Post{}).Select("posts.id").Where("posts.status = ?", "published")
+	originals = excludeHiddenUsers(originals, viewer.UserID, "posts.user_id", BlockKindBlock)
+	originals = visiblePosts(originals, viewer)
+	return db.Where("(posts.repost_of_id IS NULL OR posts.content <> '' OR posts.repost_of_id IN (?))", originals)
+}
+
+// attachReposts 为转发填充原文，并标记 viewer 已直接转发的文章。
+// 原文已删除、隐藏、viewer 无权查看或屏蔽了原文作者时设置 RepostUnavailable
+func attachReposts(posts []model.Post, viewer Actor) {
+	if len(posts) == 0 {
+		return
+	}
+	var ids, originalIDs []uint
+	for i := range posts {
+		ids = append(ids, posts[i].ID)
+		if posts[i].RepostOfID != nil {
+			originalIDs = append(originalIDs, *posts[i].RepostOfID)
+		}
+	}
+
+	originals := make(map[uint]*model.Post)
+	if len(originalIDs) > 0 {
+		var list []model.Post
+		db := database.DB.Model(&model.Post{}).Where("posts.id IN ? AND posts.status = ?", originalIDs, "published")
+		db = excludeHiddenUsers(db, viewer.UserID, "posts.user_id", BlockKindBlock)
+		visiblePosts(db, viewer).Preload("User").Preload("Tags").Find(&list)
+		for i := range list {
+			originals[list[i].ID] = &list[i]
+			ids = append(ids, list[i].ID)
+		}
+	}
+
+	reposted := make(map[uint]bool)
+	if viewer.UserID != 0 {
+		var repostedIDs []uint
+		database.DB.Model(&model.Post{}).
+			Where("user_id = ? AND repost_of_id IN ? AND content = ?", viewer.UserID, ids, "").
+			Pluck("repost_of_id", &repostedIDs)
+		for _, id := range repostedIDs {
+			reposted[id] = true
+		}
+	}
+	for _, original := range originals {
+		original.Reposted = reposted[original.ID]
+	}
+
+	for i := range posts {
+		posts[i].Reposted = reposted[posts[i].ID]
+		if posts[i].RepostOfID == nil {
+			continue
+		}
+		if original, ok := originals[*posts[i].RepostOfID]; ok {
+			posts[i].RepostOf = original
+		} else {
+			posts[i].RepostUnavailable = true
+		}
+	}
+}
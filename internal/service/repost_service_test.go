@@ -0,0 +1,70 @@
+package service
+
+import (
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"testing"
+)
+
+// setupRepost 创建原文作者、转发者和查看者，转发者直接转发了作者的一篇文章
+func setupRepost(t *testing.T) (author, reposter, viewer *model.User) {
+	t.Helper()
+	author = createTestUser(t, "password")
+	reposter = createTestUser(t, "password")
+	viewer = createTestUser(t, "password")
+	original := model.Post{Title: "original", Content: "content", UserID: author.ID, Status: "published"}
+	if err := database.DB.Create(&original).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repostService.ToggleRepost(reposter.ID, original.ID); err != nil {
+		t.Fatalf("repost: %v", err)
+	}
+	return author, reposter, viewer
+}
+
+// profileTotal 返回 viewer 在转发者主页上看到的文章数
+func profileTotal(t *testing.T, reposterID uint, viewer Actor) int64 {
+	t.Helper()
+	posts, total, err := new(PostService).GetPostList(1, 20, "", 0, reposterID, "", "", "", viewer)
+	if err != nil {
+		t.Fatalf("post list: %v", err)
+	}
+	if int64(len(posts)) != total {
+		t.Fatalf("got %d posts, total %d", len(posts), total)
+	}
+	return total
+}
+
+func TestRepostHiddenWhenOriginalAuthorBlocked(t *testing.T) {
+	setupTestDB(t)
+	author, reposter, viewer := setupRepost(t)
+
+	if got := profileTotal(t, reposter.ID, Actor{UserID: viewer.ID}); got != 1 {
+		t.Fatalf("before block: total = %d, want 1", got)
+	}
+	if err := blockService.Block(viewer.ID, author.ID); err != nil {
+		t.Fatalf("block: %v", err)
+	}
+	if got := profileTotal(t, reposter.ID, Actor{UserID: viewer.ID}); got != 0 {
+		t.Fatalf("after block: total = %d, want 0", got)
+	}
+	if got := profileTotal(t, reposter.ID, Actor{}); got != 1 {
+		t.Fatalf("anonymous viewer: total = %d, want 1", got)
+	}
+}
+
+func TestRepostHiddenWhenOriginalAuthorPrivate(t *testing.T) {
+	setupTestDB(t)
+	author, reposter, viewer := setupRepost(t)
+	database.DB.Model(author).Update("is_private", true)
+
+	if got := profileTotal(t, reposter.ID, Actor{UserID: viewer.ID}); got != 0 {
+		t.Fatalf("non-follower: total = %d, want 0", got)
+	}
+	if got := profileTotal(t, reposter.ID, Actor{UserID: author.ID}); got != 1 {
+		t.Fatalf("author: total = %d, want 1", got)
+	}
+	if got := profileTotal(t, reposter.ID, Actor{UserID: viewer.ID, Role: "admin"}); got != 1 {
+		t.Fatalf("admin: total = %d, want 1", got)
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const maxSeriesPosts = 200
+
+// SeriesService 系列的创建、编辑和文章排序
+type SeriesService struct{}
+
+var seriesService = new(SeriesService)
+
+// CreateSeries 创建系列，postIDs 为按顺序排列的文章，可以为空
+func (s *SeriesService) CreateSeries(actor Actor, title, description string, postIDs []uint) (*model.Series, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	series := model.Series{UserID: actor.UserID, Title: title, Description: description}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		return s.replacePosts(tx, &series, postIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+// GetSeries 获取系列及其中按顺序排列的文章。隐藏的文章只有作者和管理员可见
+func (s *SeriesService) GetSeries(id uint, viewer Actor) (*model.Series, error) {
+	var series model.Series
+	if err := database.DB.Preload("User").First(&series, id).Error; err != nil {
+		return nil, errors.New("series not found")
+	}
+
+	db := database.DB.Model(&model.Post{}).
+		Joins("JOIN series_posts ON series_posts.post_id = posts.id").
+		Where("series_posts.series_id = ?", series.ID)
+	if series.UserID != viewer.UserID && !viewer.IsAdmin() {
+		db = db.Where("posts.status = ?", "published")
+	}
+	db = visiblePosts(db, viewer)
+	if err := db.Preload("Tags").Order("series_posts.position asc").Find(&series.Posts).Error; err != nil {
+		return nil, err
+	}
+	series.PostCount = len(series.Posts)
+	return &series, nil
+}
+
+// ListUserSeries 列出用户的系列，PostCount 只统计已发布的文章
+func (s *SeriesService) ListUserSeries(userID uint) ([]model.Series, error) {
+	var list []model.Series
+	err := database.DB.Model(&model.Series{}).
+		Select("series.*, "+
+			"(SELECT COUNT(*) FROM series_posts JOIN posts ON posts.id = series_posts.post_id "+
+			"WHERE series_posts.series_id = series.id AND posts.status = 'published' AND posts.deleted_at IS NULL) as post_count").
+		Where("user_id = ?", userID).
+		Order("created_at desc").
+		Find(&list).Error
+	return list, err
+}
+
+// UpdateSeries 修改系列的标题和简介
+func (s *SeriesService) UpdateSeries(id uint, actor Actor, title, description string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return errors.New("title is required")
+	}
+
+	series, err := s.ownedSeries(id, actor)
+	if err != nil {
+		return err
+	}
+	before := *series
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		series.Title = title
+		series.Description = description
+		if err := tx.Model(series).Updates(map[string]interface{}{
+			"title":       title,
+			"description": description,
+		}).Error; err != nil {
+			return err
+		}
+		if series.UserID != actor.UserID {
+			return auditService.record(tx, actor, "series.admin_update", "series", series.ID, before, series)
+		}
+		return nil
+	})
+}
+
+// SetSeriesPosts 按给定顺序重新设置系列中的文章，可同时完成添加、移除和排序
+func (s *SeriesService) SetSeriesPosts(id uint, actor Actor, postIDs []uint) error {
+	series, err := s.ownedSeries(id, actor)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.replacePosts(tx, series, postIDs); err != nil {
+			return err
+		}
+		if series.UserID != actor.UserID {
+			return auditService.record(tx, actor, "series.admin_reorder", "series", series.ID, nil, postIDs)
+		}
+		return nil
+	})
+}
+
+// DeleteSeries 删除系列，其中的文章不受影响
+func (s *SeriesService) DeleteSeries(id uint, actor Actor) error {
+	series, err := s.ownedSeries(id, actor)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("series_id = ?", series.ID).Delete(&model.SeriesPost{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(series).Error; err != nil {
+			return err
+		}
+		if series.UserID != actor.UserID {
+			return auditService.record(tx, actor, "series.admin_delete", "series", series.ID, series, nil)
+		}
+		return nil
+	})
+}
+
+// PostSeriesInfo 返回文章所属系列的信息及前后篇，文章不属于任何系列时返回 nil。
+// 前后篇只在已发布的文章中查找
+func (s *SeriesService) PostSeriesInfo(post *model.Post) *model.PostSeriesInfo {
+	var link model.SeriesPost
+	if err := database.DB.Where("post_id = ?", post.ID).First(&link).Error; err != nil {
+		return nil
+	}
+	var series model.Series
+	if err := database.DB.First(&series, link.SeriesID).Error; err != nil {
+		return nil
+	}
+
+	var posts []model.Post
+	database.DB.Model(&model.Post{}).
+		Select("posts.id, posts.title, posts.slug").
+		Joins("JOIN series_posts ON series_posts.post_id = posts.id").
+		Where("series_posts.series_id = ? AND (posts.status = ? OR posts.id = ?)", series.ID, "published", post.ID).
+		Order("series_posts.position asc").
+		Find(&posts)
+
+	info := &model.PostSeriesInfo{ID: series.ID, Title: series.Title, Total: len(posts)}
+	for i, p := range posts {
+		if p.ID != post.ID {
+			continue
+		}
+		info.Position = i + 1
+		if i > 0 {
+			info.Prev = &model.SeriesPostRef{ID: posts[i-1].ID, Title: posts[i-1].Title, Slug: posts[i-1].Slug}
+		}
+		if i < len(posts)-1 {
+			info.Next = &model.SeriesPostRef{ID: posts[i+1].ID, Title: posts[i+1].Title, Slug: posts[i+1].Slug}
+		}
+		break
+	}
+	return info
+}
+
+// ownedSeries 查找系列并校验操作权限，作者本人或管理员可以修改
+func (s *SeriesService) ownedSeries(id uint, actor Actor) (*model.Series, error) {
+	var series model.Series
+	if err := database.DB.First(&series, id).Error; err != nil {
+		return nil, errors.New("series not found")
+	}
+	if series.UserID != actor.UserID && !actor.IsAdmin() {
+		return nil, errors.New("unauthorized")
+	}
+	return &series, nil
+}
+
+// replacePosts 用 postIDs 替换系列中的文章，文章必须属于系列作者且不在其他系列中
+func (s *SeriesService) replacePosts(tx *gorm.DB, series *model.Series, postIDs []uint) error {
+	if len(postIDs) > maxSeriesPosts {
+		return errors.New("too many posts in series")
+	}
+	seen := make(map[uint]bool, len(postIDs))
+	for _, id := range postIDs {
+		if seen[id] {
+			return errors.New("duplicate post in series")
+		}
+		seen[id] = true
+	}
+
+	if len(postIDs) > 0 {
+		var count int64
+		if err := tx.Model(&model.Post{}).Where("id IN ? AND user_id = ?", postIDs, series.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if int(count) != len(postIDs) {
+			return errors.New("post not found")
+		}
+
+		var taken int64
+		if err := tx.Model(&model.SeriesPost{}).Where("post_id IN ? AND series_id <> ?", postIDs, series.ID).Count(&taken).Error; err != nil {
+			return err
+		}
+		if taken > 0 {
+			return errors.New("post already in another series")
+		}
+	}
+
+	if err := tx.Where("series_id = ?", series.ID).Delete(&model.SeriesPost{}).Error; err != nil {
+		return err
+	}
+	if len(postIDs) == 0 {
+		return nil
+	}
+
+	links := make([]model.SeriesPost, len(postIDs))
+	for i, id := range postIDs {
+		links[i] = model.SeriesPost{SeriesID: series.ID, PostID: id, Position: i + 1}
+	}
+	return tx.Create(&links).Error
+}
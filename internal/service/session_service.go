@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ClientInfo 发起登录的客户端信息
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// 最近活跃时间的更新间隔，避免每个请求都写库
+const sessionTouchInterval = time.Minute
+
+// SessionService 管理登录会话，使无状态的 JWT 也能被立即注销
+type SessionService struct{}
+
+var sessionService = new(SessionService)
+
+// CreateSession 为一次登录创建会话，返回写入 JWT 的会话标识
+func (s *SessionService) CreateSession(userID uint, client ClientInfo, ttl time.Duration) (string, error) {
+	sid, err := randomToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	session := model.UserSession{
+		UserID:     userID,
+		SessionID:  sid,
+		UserAgent:  truncate(client.UserAgent, 255),
+		IP:         client.IP,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return "", err
+	}
+
+	// 顺便清理该用户已过期的会话
+	database.DB.Unscoped().Where("user_id = ? AND expires_at < ?", userID, now).Delete(&model.UserSession{})
+	return sid, nil
+}
+
+// ValidateSession 校验会话仍然有效，并更新最近活跃时间和 IP
+func (s *SessionService) ValidateSession(userID uint, sid, ip string) error {
+	if sid == "" {
+		return errors.New("session required")
+	}
+
+	var session model.UserSession
+	if err := database.DB.Where("session_id = ? AND user_id = ?", sid, userID).First(&session).Error; err != nil {
+		return errors.New("session revoked")
+	}
+	now := time.Now()
+	if now.After(session.ExpiresAt) {
+		return errors.New("session expired")
+	}
+
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		database.DB.Model(&model.UserSession{}).Where("id = ?", session.ID).Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"ip":           ip,
+		})
+	}
+	return nil
+}
+
+// ListSessions 列出用户当前有效的会话，并标记发起请求的会话
+func (s *SessionService) ListSessions(userID uint, currentSID string) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+	err := database.DB.Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].IsCurrent = sessions[i].SessionID == currentSID
+	}
+	return sessions, nil
+}
+
+// RevokeSession 注销指定会话
+func (s *SessionService) RevokeSession(userID, id uint) error {
+	result := database.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&model.UserSession{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("session not found")
+	}
+	return nil
+}
+
+// RevokeCurrentSession 退出登录
+func (s *SessionService) RevokeCurrentSession(userID uint, sid string) error {
+	return database.DB.Where("user_id = ? AND session_id = ?", userID, sid).Delete(&model.UserSession{}).Error
+}
+
+// RevokeOtherSessions 注销除当前会话以外的所有会话
+func (s *SessionService) RevokeOtherSessions(userID uint, currentSID string) error {
+	return database.DB.Where("user_id = ? AND session_id <> ?", userID, currentSID).Delete(&model.UserSession{}).Error
+}
+
+// RevokeAllSessions 在事务 tx 中注销用户的所有会话，用于重置密码等场景
+func (s *SessionService) RevokeAllSessions(tx *gorm.DB, userID uint) error {
+	return tx.Where("user_id = ?", userID).Delete(&model.UserSession{}).Error
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// SettingRequireAdmin2FA 为 true 时管理员必须启用两步验证才能行使管理员权限
+	SettingRequireAdmin2FA = "require_admin_2fa"
+	// SettingRegistrationMode 注册方式：open 开放注册、invite 凭邀请码注册、closed 关闭注册
+	SettingRegistrationMode = "registration_mode"
+	// SettingUserInviteQuota 普通用户每 30 天可生成的邀请码数量，0 表示不允许
+	SettingUserInviteQuota = "user_invite_quota"
+	// SettingReactionEmojis 可用的表情回应，逗号分隔，按此顺序展示
+	SettingReactionEmojis = "reaction_emojis"
+	// 热度 = (点赞数×权重 + 评论数×权重 + 收藏数×权重 + 阅读量×权重) / (发布后的小时数 + 2)^衰减指数
+	SettingHotLikeWeight     = "hot_like_weight"
+	SettingHotCommentWeight  = "hot_comment_weight"
+	SettingHotFavoriteWeight = "hot_favorite_weight"
+	SettingHotViewWeight     = "hot_view_weight"
+	SettingHotGravity        = "hot_gravity"
+)
+
+const (
+	RegistrationOpen   = "open"
+	RegistrationInvite = "invite"
+	RegistrationClosed = "closed"
+)
+
+// settingDefaults 支持的设置项及默认值
+var settingDefaults = map[string]string{
+	SettingRequireAdmin2FA:   "false",
+	SettingRegistrationMode:  RegistrationOpen,
+	SettingUserInviteQuota:   "3",
+	SettingReactionEmojis:    "👍,❤️,😄,🎉,😕,👀",
+	SettingHotLikeWeight:     "1",
+	SettingHotCommentWeight:  "2",
+	SettingHotFavoriteWeight: "3",
+	SettingHotViewWeight:     "0.05",
+	SettingHotGravity:        "1.8",
+}
+
+// settingValidators 设置项的取值校验
+var settingValidators = map[string]func(string) bool{
+	SettingRequireAdmin2FA:   isBoolSetting,
+	SettingRegistrationMode:  oneOfSetting(RegistrationOpen, RegistrationInvite, RegistrationClosed),
+	SettingUserInviteQuota:   isNonNegativeIntSetting,
+	SettingReactionEmojis:    isEmojiListSetting,
+	SettingHotLikeWeight:     isNonNegativeFloatSetting,
+	SettingHotCommentWeight:  isNonNegativeFloatSetting,
+	SettingHotFavoriteWeight: isNonNegativeFloatSetting,
+	SettingHotViewWeight:     isNonNegativeFloatSetting,
+	SettingHotGravity:        isNonNegativeFloatSetting,
+}
+
+// 设置项读取频繁（每个请求都会检查），缓存一段时间以减少数据库查询；
+// 多实例部署时其他实例最多在 settingCacheTTL 后生效
+const settingCacheTTL = 30 * time.Second
+
+var settingCache = struct {
+	sync.RWMutex
+	values   map[string]string
+	loadedAt time.Time
+}{}
+
+type SettingService struct{}
+
+var settingService = new(SettingService)
+
+// All 返回所有设置项（包含默认值）
+func (s *SettingService) All() map[string]string {
+	settingCache.RLock()
+	if settingCache.values != nil && time.Since(settingCache.loadedAt) < settingCacheTTL {
+		values := copySettings(settingCache.values)
+		settingCache.RUnlock()
+		return values
+	}
+	settingCache.RUnlock()
+
+	values := copySettings(settingDefaults)
+	var settings []model.Setting
+	if err := database.DB.Find(&settings).Error; err == nil {
+		for _, setting := range settings {
+			if _, ok := settingDefaults[setting.Key]; ok {
+				values[setting.Key] = setting.Value
+			}
+		}
+	}
+
+	settingCache.Lock()
+	settingCache.values = values
+	settingCache.loadedAt = time.Now()
+	settingCache.Unlock()
+	return copySettings(values)
+}
+
+func (s *SettingService) Get(key string) string {
+	return s.All()[key]
+}
+
+func (s *SettingService) GetBool(key string) bool {
+	return s.Get(key) == "true"
+}
+
+func (s *SettingService) GetInt(key string) int {
+	v, _ := strconv.Atoi(s.Get(key))
+	return v
+}
+
+func (s *SettingService) GetFloat(key string) float64 {
+	v, _ := strconv.ParseFloat(s.Get(key), 64)
+	return v
+}
+
+// Set 修改设置项（仅管理员），并记录审计日志
+func (s *SettingService) Set(key, value string, actor Actor) error {
+	if !actor.IsAdmin() {
+		return errors.New("unauthorized")
+	}
+	validate, ok := settingValidators[key]
+	if !ok {
+		return errors.New("unknown setting")
+	}
+	if !validate(value) {
+		return errors.New("invalid setting value")
+	}
+
+	// 开启管理员强制两步验证前，操作者自己必须已启用，避免把自己锁在后台之外
+	if key == SettingRequireAdmin2FA && value == "true" {
+		var user model.User
+		if err := database.DB.First(&user, actor.UserID).Error; err != nil || !user.TwoFAEnabled {
+			return errors.New("enable two-factor authentication for your own account first")
+		}
+	}
+
+	oldValue := s.Get(key)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		setting := model.Setting{Key: key, Value: value}
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&setting).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "setting.update", "setting", 0,
+			map[string]string{key: oldValue}, map[string]string{key: value})
+	})
+	if err != nil {
+		return err
+	}
+
+	settingCache.Lock()
+	settingCache.values = nil
+	settingCache.Unlock()
+	return nil
+}
+
+func copySettings(src map[string]string) map[string]string {
+	dst := make(map[string]string, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func isBoolSetting(v string) bool {
+	return v == "true" || v == "false"
+}
+
+func isNonNegativeIntSetting(v string) bool {
+	n, err := strconv.Atoi(v)
+	return err == nil && n >= 0
+}
+
+func isNonNegativeFloatSetting(v string) bool {
+	n, err := strconv.ParseFloat(v, 64)
+	return err == nil && n >= 0 && !math.IsInf(n, 0)
+}
+
+// isEmojiListSetting 逗号分隔的表情列表，1 到 20 个，每个不超过 32 字节且不重复
+func isEmojiListSetting(v string) bool {
+	items := strings.Split(v, ",")
+	if len(items) == 0 || len(items) > 20 {
+		return false
+	}
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || len(item) > 32 || seen[item] {
+			return false
+		}
+		seen[item] = true
+	}
+	return true
+}
+
+func oneOfSetting(options ...string) func(string) bool {
+	return func(v string) bool {
+		for _, option := range options {
+			if v == option {
+				return true
+			}
+		}
+		return false
+	}
+}
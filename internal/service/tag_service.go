@@ -0,0 +1,390 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+)
+
+const maxTagNameLength = 50
+
+// TagService 标签的规范化、重命名、合并、同义词管理以及关注
+type TagService struct{}
+
+var tagService = new(TagService)
+
+// normalizeTagName 统一全半角并合并空白，返回展示用名称和用于查重的小写名称
+func normalizeTagName(name string) (string, string) {
+	display := strings.Join(strings.Fields(norm.NFKC.String(name)), " ")
+	return display, strings.ToLower(display)
+}
+
+// resolveTags 将作者填写的标签名解析为标签：先查同义词，再按规范化名称查找，都没有时创建
+func resolveTags(tx *gorm.DB, names []string) ([]model.Tag, error) {
+	var tags []model.Tag
+	seen := make(map[uint]bool)
+	for _, name := range names {
+		display, key := normalizeTagName(name)
+		if display == "" {
+			continue
+		}
+		if utf8.RuneCountInString(display) > maxTagNameLength {
+			return nil, errors.New("tag name too long")
+		}
+
+		tag, err := findTag(tx, key)
+		if err != nil {
+			tag = &model.Tag{Name: display, NormalizedName: &key}
+			if err := tx.Create(tag).Error; err != nil {
+				return nil, err
+			}
+		}
+		if !seen[tag.ID] {
+			seen[tag.ID] = true
+			tags = append(tags, *tag)
+		}
+	}
+	return tags, nil
+}
+
+// findTag 按规范化名称查找标签，同义词优先
+func findTag(tx *gorm.DB, key string) (*model.Tag, error) {
+	var tag model.Tag
+	var synonym model.TagSynonym
+	if err := tx.Where("name = ?", key).First(&synonym).Error; err == nil {
+		if err := tx.First(&tag, synonym.TagID).Error; err == nil {
+			return &tag, nil
+		}
+	}
+	if err := tx.Where("normalized_name = ?", key).First(&tag).Error; err != nil {
+		return nil, errors.New("tag not found")
+	}
+	return &tag, nil
+}
+
+// RenameTag 重命名标签（仅管理员），旧名称保留为同义词，已使用旧名称的写法仍会归入该标签
+func (s *TagService) RenameTag(id uint, actor Actor, name string) (*model.Tag, error) {
+	if !actor.IsAdmin() {
+		return nil, errors.New("unauthorized")
+	}
+	display, key := normalizeTagName(name)
+	if display == "" || utf8.RuneCountInString(display) > maxTagNameLength {
+		return nil, errors.New("invalid tag name")
+	}
+
+	var tag model.Tag
+	if err := database.DB.First(&tag, id).Error; err != nil {
+		return nil, errors.New("tag not found")
+	}
+	before := tag
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if existing, err := findTag(tx, key); err == nil && existing.ID != tag.ID {
+			return errors.New("tag already exists")
+		}
+		// 新名称原本是该标签的同义词时不再需要
+		if err := tx.Unscoped().Where("name = ?", key).Delete(&model.TagSynonym{}).Error; err != nil {
+			return err
+		}
+		if tag.NormalizedName != nil && *tag.NormalizedName != key {
+			if err := addSynonym(tx, tag.ID, *tag.NormalizedName); err != nil {
+				return err
+			}
+		}
+
+		tag.Name = display
+		tag.NormalizedName = &key
+		if err := tx.Model(&tag).Updates(map[string]interface{}{"name": display, "normalized_name": key}).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "tag.rename", "tag", tag.ID, before, tag)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// MergeTags 将 sourceIDs 对应的标签合并到目标标签（仅管理员）。
+// 文章关联、关注者和同义词转移到目标标签，被合并标签的名称成为目标标签的同义词
+func (s *TagService) MergeTags(targetID uint, sourceIDs []uint, actor Actor) error {
+	if !actor.IsAdmin() {
+		return errors.New("unauthorized")
+	}
+	if len(sourceIDs) == 0 {
+		return errors.New("no tags to merge")
+	}
+
+	var target model.Tag
+	if err := database.DB.First(&target, targetID).Error; err != nil {
+		return errors.New("tag not found")
+	}
+	var sources []model.Tag
+	if err := database.DB.Where("id IN ?", sourceIDs).Find(&sources).Error; err != nil {
+		return err
+	}
+	if len(sources) != len(sourceIDs) {
+		return errors.New("tag not found")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range sources {
+			if sources[i].ID == target.ID {
+				return errors.New("cannot merge a tag into itself")
+			}
+			if err := mergeTag(tx, &sources[i], &target); err != nil {
+				return err
+			}
+		}
+		return auditService.record(tx, actor, "tag.merge", "tag", target.ID, sources, target)
+	})
+}
+
+// FollowTag 关注标签，name 支持同义词和不同大小写的写法
+func (s *TagService) FollowTag(userID uint, name string) (*model.Tag, error) {
+	_, key := normalizeTagName(name)
+	tag, err := findTag(database.DB, key)
+	if err != nil {
+		return nil, err
+	}
+	user := model.User{}
+	user.ID = userID
+	if err := database.DB.Model(&user).Association("FollowedTags").Append(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// UnfollowTag 取消关注标签
+func (s *TagService) UnfollowTag(userID uint, name string) error {
+	_, key := normalizeTagName(name)
+	tag, err := findTag(database.DB, key)
+	if err != nil {
+		return err
+	}
+	user := model.User{}
+	user.ID = userID
+	return database.DB.Model(&user).Association("FollowedTags").Delete(tag)
+}
+
+// GetFollowedTags 获取用户关注的标签
+func (s *TagService) GetFollowedTags(userID uint) ([]*model.Tag, error) {
+	var user model.User
+	if err := database.DB.Preload("FollowedTags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("tags.name asc")
+	}).First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return user.FollowedTags, nil
+}
+
+// minTrendingUses 当前窗口内至少被使用的次数，避免偶然出现一两次的标签排在前面
+const minTrendingUses = 2
+
+// TrendingTags 返回 window（24h、7d、30d）内使用次数增长最快的标签，
+// 增长率 = (当前窗口使用次数 - 上一窗口使用次数) / (上一窗口使用次数 + 1)，只统计公开账号已发布的文章
+func (s *TagService) TrendingTags(window string, limit int) ([]model.TrendingTag, error) {
+	d, err := parseHotWindow(window)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	now := time.Now()
+	current, previous := now.Add(-d), now.Add(-2*d)
+	var rows []struct {
+		TagID    uint
+		Uses     int64
+		Previous int64
+	}
+	err = database.DB.Table("post_tags").
+		Select("post_tags.tag_id, "+
+			"SUM(CASE WHEN posts.created_at >= ? THEN 1 ELSE 0 END) as uses, "+
+			"SUM(CASE WHEN posts.created_at < ? THEN 1 ELSE 0 END) as previous", current, current).
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.deleted_at IS NULL AND posts.status = 'published'").
+		Where("posts.created_at >= ?", previous).
+		Where("posts.user_id IN (SELECT id FROM users WHERE is_private = ?)", false).
+		Group("post_tags.tag_id").
+		Having("uses >= ?", minTrendingUses).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	trending := make([]model.TrendingTag, 0, len(rows))
+	for _, row := range rows {
+		if row.Uses <= row.Previous {
+			continue
+		}
+		trending = append(trending, model.TrendingTag{
+			Tag:      model.Tag{Model: gorm.Model{ID: row.TagID}},
+			Uses:     row.Uses,
+			Previous: row.Previous,
+			Growth:   float64(row.Uses-row.Previous) / float64(row.Previous+1),
+		})
+	}
+	sort.Slice(trending, func(i, j int) bool {
+		if trending[i].Growth != trending[j].Growth {
+			return trending[i].Growth > trending[j].Growth
+		}
+		if trending[i].Uses != trending[j].Uses {
+			return trending[i].Uses > trending[j].Uses
+		}
+		return trending[i].Tag.ID < trending[j].Tag.ID
+	})
+	if len(trending) > limit {
+		trending = trending[:limit]
+	}
+
+	ids := make([]uint, len(trending))
+	for i := range trending {
+		ids[i] = trending[i].Tag.ID
+	}
+	var tags []model.Tag
+	if len(ids) > 0 {
+		if err := database.DB.Where("id IN ?", ids).Find(&tags).Error; err != nil {
+			return nil, err
+		}
+	}
+	byID := make(map[uint]model.Tag, len(tags))
+	for _, tag := range tags {
+		byID[tag.ID] = tag
+	}
+	for i := range trending {
+		trending[i].Tag = byID[trending[i].Tag.ID]
+	}
+	return trending, nil
+}
+
+// ListSynonyms 列出标签的同义词
+func (s *TagService) ListSynonyms(tagID uint) ([]model.TagSynonym, error) {
+	var synonyms []model.TagSynonym
+	err := database.DB.Where("tag_id = ?", tagID).Order("name asc").Find(&synonyms).Error
+	return synonyms, err
+}
+
+// AddSynonym 为标签添加同义词（仅管理员）。同义词已是独立标签时应使用合并
+func (s *TagService) AddSynonym(tagID uint, actor Actor, name string) (*model.TagSynonym, error) {
+	if !actor.IsAdmin() {
+		return nil, errors.New("unauthorized")
+	}
+	display, key := normalizeTagName(name)
+	if display == "" || utf8.RuneCountInString(display) > maxTagNameLength {
+		return nil, errors.New("invalid tag name")
+	}
+
+	var tag model.Tag
+	if err := database.DB.First(&tag, tagID).Error; err != nil {
+		return nil, errors.New("tag not found")
+	}
+
+	var synonym model.TagSynonym
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := findTag(tx, key); err == nil {
+			return errors.New("tag already exists")
+		}
+		synonym = model.TagSynonym{Name: key, TagID: tag.ID}
+		if err := tx.Create(&synonym).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "tag.synonym_add", "tag", tag.ID, nil, synonym)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &synonym, nil
+}
+
+// RemoveSynonym 删除标签的同义词（仅管理员），已关联的文章不受影响
+func (s *TagService) RemoveSynonym(tagID, synonymID uint, actor Actor) error {
+	if !actor.IsAdmin() {
+		return errors.New("unauthorized")
+	}
+
+	var synonym model.TagSynonym
+	if err := database.DB.Where("id = ? AND tag_id = ?", synonymID, tagID).First(&synonym).Error; err != nil {
+		return errors.New("synonym not found")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&synonym).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "tag.synonym_remove", "tag", tagID, synonym, nil)
+	})
+}
+
+// NormalizeTags 为引入规范化名称之前创建的标签补充规范化名称，
+// 规范化后重名的标签合并到最早创建的那个
+func (s *TagService) NormalizeTags() {
+	for {
+		var tags []model.Tag
+		if err := database.DB.Where("normalized_name IS NULL").Order("id asc").Limit(100).Find(&tags).Error; err != nil {
+			fmt.Printf("TagService: failed to load tags without normalized name: %v\n", err)
+			return
+		}
+		if len(tags) == 0 {
+			return
+		}
+		for i := range tags {
+			_, key := normalizeTagName(tags[i].Name)
+			err := database.DB.Transaction(func(tx *gorm.DB) error {
+				var existing model.Tag
+				if err := tx.Where("normalized_name = ?", key).First(&existing).Error; err == nil {
+					return mergeTag(tx, &tags[i], &existing)
+				}
+				return tx.Model(&tags[i]).Update("normalized_name", key).Error
+			})
+			if err != nil {
+				fmt.Printf("TagService: failed to normalize tag %d: %v\n", tags[i].ID, err)
+				return
+			}
+		}
+	}
+}
+
+// mergeTag 将 source 的文章关联、关注者和同义词转移到 target 并删除 source
+func mergeTag(tx *gorm.DB, source, target *model.Tag) error {
+	results := []*gorm.DB{
+		tx.Exec("INSERT INTO post_tags (post_id, tag_id) SELECT post_id, ? FROM post_tags WHERE tag_id = ? "+
+			"AND post_id NOT IN (SELECT post_id FROM (SELECT post_id FROM post_tags WHERE tag_id = ?) AS t)",
+			target.ID, source.ID, target.ID),
+		tx.Exec("DELETE FROM post_tags WHERE tag_id = ?", source.ID),
+		tx.Exec("INSERT INTO user_tag_follows (user_id, tag_id) SELECT user_id, ? FROM user_tag_follows WHERE tag_id = ? "+
+			"AND user_id NOT IN (SELECT user_id FROM (SELECT user_id FROM user_tag_follows WHERE tag_id = ?) AS t)",
+			target.ID, source.ID, target.ID),
+		tx.Exec("DELETE FROM user_tag_follows WHERE tag_id = ?", source.ID),
+		tx.Model(&model.TagSynonym{}).Where("tag_id = ?", source.ID).Update("tag_id", target.ID),
+		tx.Unscoped().Delete(source),
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			return result.Error
+		}
+	}
+
+	_, key := normalizeTagName(source.Name)
+	if target.NormalizedName != nil && *target.NormalizedName == key {
+		return nil
+	}
+	return addSynonym(tx, target.ID, key)
+}
+
+// addSynonym 添加同义词，已存在时改为指向该标签
+func addSynonym(tx *gorm.DB, tagID uint, key string) error {
+	var synonym model.TagSynonym
+	if err := tx.Where("name = ?", key).First(&synonym).Error; err == nil {
+		return tx.Model(&synonym).Update("tag_id", tagID).Error
+	}
+	return tx.Create(&model.TagSynonym{Name: key, TagID: tagID}).Error
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"simple-blog/internal/config"
+	"simple-blog/internal/database"
+	"simple-blog/internal/model"
+	"simple-blog/internal/totp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeCount = 10
+
+// TwoFactorService 基于 TOTP 的两步验证
+type TwoFactorService struct{}
+
+var twoFactorService = new(TwoFactorService)
+
+// Enroll 生成新的 TOTP 密钥，返回密钥和 otpauth 链接；需调用 Confirm 校验后才会启用
+func (s *TwoFactorService) Enroll(userID uint) (string, string, error) {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return "", "", errors.New("user not found")
+	}
+	if user.TwoFAEnabled {
+		return "", "", errors.New("two-factor authentication already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := database.DB.Model(&user).Update("two_fa_secret", secret).Error; err != nil {
+		return "", "", err
+	}
+
+	uri := totp.URI(config.GetAppConfig().Name, user.Username, secret)
+	return secret, uri, nil
+}
+
+// Confirm 校验认证器生成的验证码并启用两步验证，返回一次性恢复码（仅此次可见）
+func (s *TwoFactorService) Confirm(userID uint, code string) ([]string, error) {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.TwoFAEnabled {
+		return nil, errors.New("two-factor authentication already enabled")
+	}
+	if user.TwoFASecret == "" {
+		return nil, errors.New("two-factor enrollment not started")
+	}
+
+	step, ok := totp.Validate(user.TwoFASecret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid verification code")
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_fa_enabled":   true,
+			"two_fa_last_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable 关闭两步验证，需要同时提供密码和验证码（或恢复码）
+func (s *TwoFactorService) Disable(userID uint, password, code string) error {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if !user.TwoFAEnabled {
+		return errors.New("two-factor authentication not enabled")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return errors.New("password is incorrect")
+	}
+	if err := s.Verify(&user, code); err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"two_fa_enabled":   false,
+			"two_fa_secret":    "",
+			"two_fa_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("user_id = ?", user.ID).Delete(&model.RecoveryCode{}).Error
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部作废
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.TwoFAEnabled {
+		return nil, errors.New("two-factor authentication not enabled")
+	}
+	if err := s.Verify(&user, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	return codes, err
+}
+
+// GetStatus 返回两步验证状态及剩余可用的恢复码数量
+func (s *TwoFactorService) GetStatus(userID uint) (map[string]interface{}, error) {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	var remaining int64
+	database.DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&remaining)
+
+	return map[string]interface{}{
+		"enabled":                  user.TwoFAEnabled,
+		"recovery_codes_remaining": remaining,
+	}, nil
+}
+
+// Verify 校验 TOTP 验证码或恢复码。同一时间窗口的验证码和已使用的恢复码都不能再次使用
+func (s *TwoFactorService) Verify(user *model.User, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(user.TwoFASecret, code, time.Now()); ok {
+		result := database.DB.Model(&model.User{}).
+			Where("id = ? AND two_fa_last_step < ?", user.ID, step).
+			Update("two_fa_last_step", step)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("verification code already used")
+		}
+		return nil
+	}
+
+	result := database.DB.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, hashToken(normalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("invalid verification code")
+	}
+	return nil
+}
+
+func (s *TwoFactorService) replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Unscoped().Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]model.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, model.RecoveryCode{
+			UserID:   userID,
+			CodeHash: hashToken(normalizeRecoveryCode(code)),
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode 生成形如 abcde-fghij 的恢复码
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	s := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))[:10]
+	return s[:5] + "-" + s[5:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+// TwoFactorSetupRequired 判断管理员是否因站点策略需要先启用两步验证，
+// 未启用前不授予管理员权限
+func TwoFactorSetupRequired(user *model.User) bool {
+	return user.Role == "admin" && !user.TwoFAEnabled && settingService.GetBool(SettingRequireAdmin2FA)
+}
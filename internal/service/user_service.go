@@ -2,21 +2,38 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
 	"simple-blog/internal/config"
 	"simple-blog/internal/database"
 	"simple-blog/internal/model"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type UserService struct{}
 
-func (s *UserService) Register(username, password, email string) error {
-	var user model.User
-	if err := database.DB.Where("username = ?", username).First(&user).Error; err == nil {
-		return errors.New("user already exists")
+var userService = new(UserService)
+
+// Register 注册新用户。需要通过验证码，邀请注册模式下还需要有效的邀请码
+func (s *UserService) Register(username, password, email, inviteCode string, captcha CaptchaAnswer) error {
+	mode := inviteService.RegistrationMode()
+	if mode == RegistrationClosed {
+		return errors.New("registration is closed")
+	}
+	if err := captchaService.Verify(captcha); err != nil {
+		return err
+	}
+
+	if err := validateUsername(username); err != nil {
+		return err
+	}
+	if err := usernameAvailable(database.DB, username, 0); err != nil {
+		return err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
@@ -30,50 +47,244 @@ func (s *UserService) Register(username, password, email string) error {
 		Email:    email,
 	}
 
-	return database.DB.Create(&newUser).Error
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if mode == RegistrationInvite {
+			invite, err := inviteService.consumeInvite(tx, inviteCode)
+			if err != nil {
+				return err
+			}
+			newUser.InviteCodeID = &invite.ID
+		}
+		return tx.Create(&newUser).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// 发送邮箱验证邮件，发送失败不影响注册，用户可稍后重新发送
+	if err := accountService.requestEmailVerification(&newUser, email); err != nil {
+		fmt.Printf("Register: failed to send verification email to %s: %v\n", email, err)
+	}
+	return nil
+}
+
+// LoginResult 登录结果。启用两步验证的用户第一步只会拿到 ChallengeToken
+type LoginResult struct {
+	Token                  string `json:"token,omitempty"`
+	UserID                 uint   `json:"user_id"`
+	Role                   string `json:"role,omitempty"`
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken         string `json:"challenge_token,omitempty"`
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"`
+	Linked                 bool   `json:"linked,omitempty"` // 第三方账号绑定成功（不签发令牌）
 }
 
-func (s *UserService) Login(username, password string) (string, uint, string, error) {
+const (
+	TokenTypeAccess    = "access"
+	TokenTypeChallenge = "2fa_challenge"
+
+	accessTokenTTL    = 24 * time.Hour
+	challengeTokenTTL = 5 * time.Minute
+)
+
+// Login 使用用户名或邮箱登录，均不区分大小写
+func (s *UserService) Login(identifier, password string, captcha CaptchaAnswer, client ClientInfo) (*LoginResult, error) {
+	identifier = strings.TrimSpace(identifier)
+
 	var user model.User
-	if err := database.DB.Where("username = ?", username).First(&user).Error; err != nil {
-		return "", 0, "", errors.New("invalid credentials")
+	found := false
+	if strings.Contains(identifier, "@") {
+		found = database.DB.Where("LOWER(email) = LOWER(?)", identifier).First(&user).Error == nil
+	}
+	// 早期注册的用户名可能含有 @，邮箱找不到时再按用户名查找
+	if !found {
+		found = database.DB.Where("LOWER(username) = LOWER(?)", identifier).First(&user).Error == nil
+	}
+
+	// 失败计数按账号统计，无论使用用户名还是邮箱登录
+	guardKey := strings.ToLower(identifier)
+	if found {
+		guardKey = user.Username
+	}
+	if err := loginGuardService.Check(guardKey, client.IP); err != nil {
+		return nil, err
+	}
+	if loginGuardService.CaptchaRequired(guardKey, client.IP) {
+		if err := captchaService.Verify(captcha); err != nil {
+			return nil, err
+		}
 	}
 
+	if !found {
+		loginGuardService.RecordFailure(guardKey, client.IP)
+		return nil, errors.New("invalid credentials")
+	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return "", 0, "", errors.New("invalid credentials")
+		loginGuardService.RecordFailure(guardKey, client.IP)
+		return nil, errors.New("invalid credentials")
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": user.ID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
+	return s.beginSession(&user, client)
+}
+
+// beginSession 第一步认证通过后调用：启用两步验证的用户返回挑战令牌，否则直接签发正式令牌
+func (s *UserService) beginSession(user *model.User, client ClientInfo) (*LoginResult, error) {
+	if user.TwoFAEnabled {
+		challenge, err := signToken(user.ID, TokenTypeChallenge, "", challengeTokenTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{
+			UserID:            user.ID,
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge,
+		}, nil
+	}
+
+	return s.completeLogin(user, client)
+}
+
+// LoginTwoFactor 两步登录的第二步：校验挑战令牌和验证码（或恢复码）后签发正式令牌
+func (s *UserService) LoginTwoFactor(challengeToken, code string, client ClientInfo) (*LoginResult, error) {
+	userID, err := ParseToken(challengeToken, TokenTypeChallenge)
+	if err != nil {
+		return nil, errors.New("invalid or expired challenge")
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil || !user.TwoFAEnabled {
+		return nil, errors.New("invalid or expired challenge")
+	}
+	if err := loginGuardService.Check(user.Username, client.IP); err != nil {
+		return nil, err
+	}
+	if err := twoFactorService.Verify(&user, code); err != nil {
+		loginGuardService.RecordFailure(user.Username, client.IP)
+		return nil, err
+	}
+
+	return s.completeLogin(&user, client)
+}
+
+// completeLogin 认证全部通过后创建会话并签发正式令牌
+func (s *UserService) completeLogin(user *model.User, client ClientInfo) (*LoginResult, error) {
+	loginGuardService.RecordSuccess(user.Username)
+
+	sid, err := sessionService.CreateSession(user.ID, client, accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenString, err := signToken(user.ID, TokenTypeAccess, sid, accessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LoginResult{
+		Token:                  tokenString,
+		UserID:                 user.ID,
+		Role:                   user.Role,
+		TwoFactorSetupRequired: TwoFactorSetupRequired(user),
+	}, nil
+}
+
+func signToken(userID uint, tokenType, sid string, ttl time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"typ":     tokenType,
+		"exp":     time.Now().Add(ttl).Unix(),
+	}
+	if sid != "" {
+		claims["sid"] = sid
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(config.JwtSecret)
+}
+
+// ParseToken 校验 JWT 并返回其中的用户ID
+func ParseToken(tokenString, tokenType string) (uint, error) {
+	claims, err := parseClaims(tokenString, tokenType)
+	if err != nil {
+		return 0, err
+	}
+	userIdFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, errors.New("invalid token claims")
+	}
+	return uint(userIdFloat), nil
+}
+
+// ParseAccessToken 校验正式访问令牌并确认其会话未被注销，返回用户ID和会话标识
+func ParseAccessToken(tokenString, ip string) (uint, string, error) {
+	claims, err := parseClaims(tokenString, TokenTypeAccess)
+	if err != nil {
+		return 0, "", err
+	}
+	userIdFloat, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, "", errors.New("invalid token claims")
+	}
+	userID := uint(userIdFloat)
+
+	// 没有会话的旧令牌视为过期，要求重新登录
+	sid, _ := claims["sid"].(string)
+	if sid == "" {
+		return 0, "", errors.New("token has expired")
+	}
+	if err := sessionService.ValidateSession(userID, sid, ip); err != nil {
+		return 0, "", err
+	}
+	return userID, sid, nil
+}
 
-	tokenString, err := token.SignedString(config.JwtSecret)
+func parseClaims(tokenString, tokenType string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return config.JwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
-		return "", 0, "", err
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
 	}
 
-	return tokenString, user.ID, user.Role, nil
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("invalid token claims")
+	}
+	if typ, _ := claims["typ"].(string); typ != tokenType {
+		return nil, errors.New("invalid token type")
+	}
+	return claims, nil
 }
 
-func (s *UserService) FollowUser(followerID, followedID uint) error {
+// FollowUser 关注用户。对方为私密账号时创建待批准的关注请求，返回值 pending 表示请求待对方处理
+func (s *UserService) FollowUser(followerID, followedID uint) (bool, error) {
 	if followerID == followedID {
-		return errors.New("cannot follow yourself")
+		return false, errors.New("cannot follow yourself")
 	}
 
 	var follower model.User
 	var followed model.User
 
 	if err := database.DB.First(&follower, followerID).Error; err != nil {
-		return err
+		return false, err
 	}
 	if err := database.DB.First(&followed, followedID).Error; err != nil {
-		return err
+		return false, err
+	}
+	// 任一方屏蔽了对方时不能关注
+	if blockService.HasBlocked(followedID, followerID) || blockService.HasBlocked(followerID, followedID) {
+		return false, errors.New("blocked")
 	}
 
-	return database.DB.Model(&follower).Association("Following").Append(&followed)
+	if followed.IsPrivate && !s.IsFollowing(followerID, followedID) {
+		return true, followRequestService.create(followerID, followedID)
+	}
+	return false, database.DB.Model(&follower).Association("Following").Append(&followed)
 }
 
+// UnfollowUser 取消关注，同时撤回尚未处理的关注请求
 func (s *UserService) UnfollowUser(followerID, followedID uint) error {
 	var follower model.User
 	var followed model.User
@@ -85,9 +296,38 @@ func (s *UserService) UnfollowUser(followerID, followedID uint) error {
 		return err
 	}
 
+	if err := database.DB.Where("follower_id = ? AND followed_id = ?", followerID, followedID).
+		Delete(&model.FollowRequest{}).Error; err != nil {
+		return err
+	}
 	return database.DB.Model(&follower).Association("Following").Delete(&followed)
 }
 
+// IsFollowing 判断 followerID 是否已关注 followedID
+func (s *UserService) IsFollowing(followerID, followedID uint) bool {
+	if followerID == 0 {
+		return false
+	}
+	var count int64
+	database.DB.Table("user_followers").
+		Where("follower_id = ? AND followed_id = ?", followerID, followedID).
+		Count(&count)
+	return count > 0
+}
+
+// SetPrivate 切换私密账号。改为公开账号时自动通过所有待处理的关注请求
+func (s *UserService) SetPrivate(userID uint, private bool) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Update("is_private", private).Error; err != nil {
+			return err
+		}
+		if private {
+			return nil
+		}
+		return followRequestService.approveAll(tx, userID)
+	})
+}
+
 func (s *UserService) GetFollowers(userID uint) ([]model.User, error) {
 	var user model.User
 	if err := database.DB.Preload("Followers").First(&user, userID).Error; err != nil {
@@ -128,46 +368,83 @@ func (s *UserService) GetUserProfile(targetUserID uint, currentUserID uint) (map
 	}
 
 	var postCount int64
-	database.DB.Model(&model.Post{}).Where("user_id = ?", targetUserID).Count(&postCount)
+	withoutPlainReposts(database.DB.Model(&model.Post{})).Where("user_id = ?", targetUserID).Count(&postCount)
 
 	followerCount := database.DB.Model(&user).Association("Followers").Count()
 	followingCount := database.DB.Model(&user).Association("Following").Count()
 
-	isFollowing := false
-	if currentUserID != 0 {
+	isFollowing := s.IsFollowing(currentUserID, targetUserID)
+	followRequested := false
+	if currentUserID != 0 && !isFollowing && user.IsPrivate {
 		var c int64
-		database.DB.Table("user_followers").
+		database.DB.Model(&model.FollowRequest{}).
 			Where("follower_id = ? AND followed_id = ?", currentUserID, targetUserID).
 			Count(&c)
-		isFollowing = c > 0
+		followRequested = c > 0
+	}
+
+	series, err := seriesService.ListUserSeries(targetUserID)
+	if err != nil {
+		return nil, err
 	}
 
 	return map[string]interface{}{
-		"id":              user.ID,
-		"username":        user.Username,
-		"email":           user.Email,
-		"avatar":          user.Avatar,
-		"blog_name":       user.BlogName,
-		"bio":             user.Bio,
-		"role":            user.Role,
-		"created_at":      user.CreatedAt,
-		"post_count":      postCount,
-		"follower_count":  followerCount,
-		"following_count": followingCount,
-		"is_following":    isFollowing,
+		"id":               user.ID,
+		"username":         user.Username,
+		"email":            user.Email,
+		"avatar":           user.Avatar,
+		"blog_name":        user.BlogName,
+		"bio":              user.Bio,
+		"role":             user.Role,
+		"locale":           user.Locale,
+		"created_at":       user.CreatedAt,
+		"post_count":       postCount,
+		"follower_count":   followerCount,
+		"following_count":  followingCount,
+		"is_following":     isFollowing,
+		"is_private":       user.IsPrivate,
+		"follow_requested": followRequested,
+		"is_blocked":       blockService.HasBlocked(currentUserID, targetUserID),
+		"is_muted":         blockService.HasMuted(currentUserID, targetUserID),
+		"series":           series,
 	}, nil
 }
 
-func (s *UserService) UpdateProfile(userID uint, email, avatar, blogName, bio string) error {
+// UpdateProfile 更新个人资料。修改邮箱时不会立即生效，而是向新邮箱发送验证邮件，
+// 返回值 emailPending 表示是否有待验证的新邮箱
+func (s *UserService) UpdateProfile(userID uint, email, avatar, blogName, bio, locale string) (bool, error) {
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return false, err
+	}
+
 	updates := map[string]interface{}{
-		"email":     email,
 		"blog_name": blogName,
 		"bio":       bio,
 	}
 	if avatar != "" {
 		updates["avatar"] = avatar
 	}
-	return database.DB.Model(&model.User{}).Where("id = ?", userID).Updates(updates).Error
+	if locale != "" {
+		updates["locale"] = locale
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return false, err
+	}
+
+	if email == "" || email == user.Email {
+		return false, nil
+	}
+
+	var count int64
+	database.DB.Model(&model.User{}).Where("email = ? AND id <> ?", email, userID).Count(&count)
+	if count > 0 {
+		return false, errors.New("email already in use")
+	}
+	if err := accountService.requestEmailVerification(&user, email); err != nil {
+		return false, err
+	}
+	return true, nil
 }
 
 func (s *UserService) UpdateAvatar(userID uint, avatarPath string) error {
@@ -188,3 +465,125 @@ func (s *UserService) SearchUsers(keyword string, limit int) ([]model.User, erro
 	}
 	return users, nil
 }
+
+// UpdateRole 修改用户角色（仅管理员）
+func (s *UserService) UpdateRole(targetUserID uint, role string, actor Actor) error {
+	if !actor.IsAdmin() {
+		return errors.New("unauthorized")
+	}
+	if role != "user" && role != "admin" {
+		return errors.New("invalid role")
+	}
+	if targetUserID == actor.UserID {
+		return errors.New("cannot change your own role")
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, targetUserID).Error; err != nil {
+		return errors.New("user not found")
+	}
+	if user.Role == role {
+		return nil
+	}
+
+	oldRole := user.Role
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", role).Error; err != nil {
+			return err
+		}
+		return auditService.record(tx, actor, "user.role", "user", user.ID,
+			map[string]string{"role": oldRole}, map[string]string{"role": role})
+	})
+}
+
+const (
+	usernameChangeInterval    = 30 * 24 * time.Hour // 两次修改用户名的最小间隔
+	usernameReservationPeriod = 90 * 24 * time.Hour // 旧用户名释放后保留给原用户的时长
+)
+
+var usernamePattern = regexp.MustCompile(`^[\p{L}\p{N}_.\-]{2,30}$`)
+
+// validateUsername 校验用户名格式。不允许包含 @，以便与邮箱登录区分
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return errors.New("username must be 2-30 letters, digits, '_', '-' or '.'")
+	}
+	if strings.HasPrefix(strings.ToLower(username), "deleted_") {
+		return errors.New("username is reserved")
+	}
+	return nil
+}
+
+// usernameAvailable 检查用户名是否可用（不区分大小写）。
+// 其他用户近期释放的用户名在保留期内不可使用，防止冒充；userID 为 0 表示新用户
+func usernameAvailable(tx *gorm.DB, username string, userID uint) error {
+	var count int64
+	tx.Model(&model.User{}).Where("LOWER(username) = LOWER(?) AND id <> ?", username, userID).Count(&count)
+	if count > 0 {
+		return errors.New("user already exists")
+	}
+
+	tx.Model(&model.UsernameHistory{}).
+		Where("LOWER(old_username) = LOWER(?) AND user_id <> ? AND created_at > ?", username, userID, time.Now().Add(-usernameReservationPeriod)).
+		Count(&count)
+	if count > 0 {
+		return errors.New("username is reserved")
+	}
+	return nil
+}
+
+// ChangeUsername 修改用户名，旧用户名会记录下来用于跳转，返回去掉首尾空白后保存的用户名
+func (s *UserService) ChangeUsername(userID uint, newUsername string) (string, error) {
+	newUsername = strings.TrimSpace(newUsername)
+	if err := validateUsername(newUsername); err != nil {
+		return "", err
+	}
+
+	var user model.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return "", errors.New("user not found")
+	}
+	if user.Username == newUsername {
+		return "", errors.New("username unchanged")
+	}
+
+	var last model.UsernameHistory
+	err := database.DB.Where("user_id = ?", userID).Order("created_at desc").First(&last).Error
+	if err == nil && time.Since(last.CreatedAt) < usernameChangeInterval {
+		next := last.CreatedAt.Add(usernameChangeInterval)
+		return "", fmt.Errorf("username can only be changed once every 30 days, next change available after %s", next.Format("2006-01-02"))
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := usernameAvailable(tx, newUsername, userID); err != nil {
+			return err
+		}
+		if err := tx.Create(&model.UsernameHistory{UserID: userID, OldUsername: user.Username}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.User{}).Where("id = ?", userID).Update("username", newUsername).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return newUsername, nil
+}
+
+// ResolveUsername 按用户名查找用户（不区分大小写），找不到时查找历史用户名。
+// 返回用户ID和当前用户名，调用方可据此跳转到新用户名
+func (s *UserService) ResolveUsername(username string) (uint, string, error) {
+	var user model.User
+	if err := database.DB.Where("LOWER(username) = LOWER(?)", username).First(&user).Error; err == nil {
+		return user.ID, user.Username, nil
+	}
+
+	var history model.UsernameHistory
+	err := database.DB.Where("LOWER(old_username) = LOWER(?)", username).Order("created_at desc").First(&history).Error
+	if err != nil {
+		return 0, "", errors.New("user not found")
+	}
+	if err := database.DB.First(&user, history.UserID).Error; err != nil {
+		return 0, "", errors.New("user not found")
+	}
+	return user.ID, user.Username, nil
+}
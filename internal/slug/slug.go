@@ -0,0 +1,77 @@
+// Package slug 根据文章标题生成 URL 友好的短标识，中文转换为拼音
+package slug
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength slug 的最大长度
+const MaxLength = 80
+
+var pinyinIndex = buildPinyinIndex()
+
+func buildPinyinIndex() map[rune]string {
+	index := make(map[rune]string, 7000)
+	for py, chars := range pinyinTable {
+		for _, r := range chars {
+			index[r] = py
+		}
+	}
+	return index
+}
+
+// Make 根据标题生成 slug：字母数字转为小写保留，汉字转为拼音，其余字符作为分隔符。
+// 无法转换出任何内容时返回空字符串
+func Make(title string) string {
+	var words []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	// 兼容分解：全角字符转为半角，带重音的拉丁字母拆出组合符号后丢弃
+	for _, r := range norm.NFKD.String(strings.ToLower(title)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word.WriteRune(r)
+		case unicode.Is(unicode.Han, r):
+			flush()
+			if py, ok := pinyinIndex[r]; ok {
+				words = append(words, py)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return truncate(strings.Join(words, "-"))
+}
+
+// truncate 截断到最大长度，尽量在单词边界处截断
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	s = s[:MaxLength]
+	if i := strings.LastIndex(s, "-"); i > MaxLength/2 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "-")
+}
+
+var validPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Valid 检查用户自定义的 slug 是否合法：小写字母、数字，以单个连字符分隔
+func Valid(s string) bool {
+	return len(s) <= MaxLength && validPattern.MatchString(s)
+}